@@ -1,16 +1,40 @@
 package main
 
 import (
+	"InvestmentHelpver_V2/internal/alphavantage"
+	"InvestmentHelpver_V2/internal/analytics"
+	"InvestmentHelpver_V2/internal/backtest"
+	"InvestmentHelpver_V2/internal/cache"
+	"InvestmentHelpver_V2/internal/calendar"
 	"InvestmentHelpver_V2/internal/db"
+	"InvestmentHelpver_V2/internal/fundamentals"
+	"InvestmentHelpver_V2/internal/fx"
+	"InvestmentHelpver_V2/internal/indicators"
 	"InvestmentHelpver_V2/internal/news"
+	"InvestmentHelpver_V2/internal/patterns"
 	"InvestmentHelpver_V2/internal/plot"
+	"InvestmentHelpver_V2/internal/portfolio"
+	"InvestmentHelpver_V2/internal/quote"
+	"InvestmentHelpver_V2/internal/render"
+	"InvestmentHelpver_V2/internal/search"
 	"os"
+	"time"
 
 	"github.com/jinzhu/configor"
 
+	"bytes"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"math"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 )
 
 // Структура отражающая config.yml
@@ -19,9 +43,37 @@ type Config struct {
 		Name           string `default:"dbName"`
 		Collection     string `default:"dbCollection"`
 		CollectionTest string `default:"dbCollectionTest"`
+		Portfolio      string `default:"Portfolio"` // коллекция операций портфелей пользователей
 		DBserver       string `default:"dbServer"`
 	}
-	VentageKey string `default:"key"`
+	VentageKey  string   `default:"key"`
+	VentageKeys []string // дополнительные ключи Alpha Vantage, используются по кругу вместе с VentageKey
+	VentageURL  string   `default:"https://www.alphavantage.co"` // адрес API Alpha Vantage (или прокси)
+	YahooURL    string   `default:"https://finance.yahoo.com"`   // адрес сайта Yahoo Finance (или прокси)
+	HTTP        struct {
+		Timeout int    `default:"30"` // сколько секунд ждать ответа от Alpha Vantage и Yahoo
+		Proxy   string // адрес HTTP прокси, пустая строка - прокси из переменных окружения HTTP_PROXY/HTTPS_PROXY
+	}
+	VentageLimits struct {
+		PerMinute int `default:"5"`   // запросов к Alpha Vantage в минуту на один ключ
+		PerDay    int `default:"500"` // запросов к Alpha Vantage в сутки на один ключ
+		MaxWait   int `default:"30"`  // сколько секунд запрос может ждать своей очереди
+	}
+	PlotProviders []string // поставщики графиков в порядке приоритета (alphavantage, csv), по умолчанию только alphavantage
+	CSVDir        string   `default:"data"` // каталог с CSV файлами для поставщика csv
+	Cache         struct {
+		Backend     string `default:"memory"` // memory, mongo или none
+		Size        int    `default:"1000"`   // максимальное количество записей для memory
+		Collection  string `default:"Cache"`  // коллекция MongoDB для mongo
+		NewsTTL     int    `default:"300"`    // сколько секунд хранятся новости
+		IntradayTTL int    `default:"60"`     // сколько секунд хранятся внутридневные свечи
+	}
+	Search struct {
+		Backend  string `default:"alphavantage"`      // alphavantage, listings или none
+		Listings string `default:"data/listings.csv"` // CSV файл LISTING_STATUS для listings
+		Validate bool   // проверять символы перед обращением к поставщикам данных
+	}
+	AdminToken string // токен административных запросов (заголовок Authorization: Bearer), пустая строка - только с localhost
 	LocalPort  string `default:"8888"`
 }
 
@@ -39,69 +91,774 @@ func loadConfig() Config {
 	return config
 }
 
+// Метод создающий пул ключей Alpha Vantage из VentageKey и VentageKeys (повторяющиеся ключи пропускаются)
+func newKeyPool(config Config) *alphavantage.KeyPool {
+	keys := []string{}
+	seen := map[string]bool{}
+	for _, key := range append([]string{config.VentageKey}, config.VentageKeys...) {
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return alphavantage.NewKeyPool(keys...)
+}
+
+// Метод создающий HTTP клиент для запросов к Alpha Vantage и Yahoo с таймаутом и прокси из config.yml
+func newHTTPClient(config Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.HTTP.Proxy != "" {
+		proxyURL, err := url.Parse(config.HTTP.Proxy)
+		if err != nil {
+			panic(err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	return &http.Client{Transport: transport, Timeout: time.Duration(config.HTTP.Timeout) * time.Second}
+}
+
+// Метод создающий PlotManager из поставщиков перечисленных в config.yml, поставщики опрашиваются по порядку до первого успешного ответа
+func newPlotManager(config Config) plot.PlotManager {
+	names := config.PlotProviders
+	if len(names) == 0 {
+		names = []string{"alphavantage"}
+	}
+	providers := []plot.Provider{}
+	for _, name := range names {
+		switch name {
+		case "alphavantage":
+			providers = append(providers, plot.Provider{Name: name, Manager: plot.NewPlotManagerAlphaVantageWithClient(ventageClient)})
+		case "csv":
+			providers = append(providers, plot.Provider{Name: name, Manager: plot.NewPlotManagerCSV(config.CSVDir)})
+		default:
+			panic(fmt.Sprintf("unknown plot provider %s", name))
+		}
+	}
+	return plot.NewPlotManagerFallback(providers...)
+}
+
+// Метод создающий поиск финансовых активов по настройкам из config.yml, возвращает nil если поиск выключен или файл со списком недоступен
+func newSymbolSearcher(config Config) search.SymbolSearcher {
+	switch config.Search.Backend {
+	case "none":
+		return nil
+	case "listings":
+		searcher, err := search.NewSymbolSearcherListings(config.Search.Listings)
+		if err != nil {
+			log.Print(err)
+			return nil
+		}
+		return searcher
+	}
+	return search.NewSymbolSearcherAlphaVantage(ventageClient)
+}
+
+// Метод создающий кэширующий менеджер поверх менеджеров графиков и новостей по настройкам из config.yml,
+// возвращает nil если кэш выключен или хранилище недоступно
+func newCacheManager(config Config, plotManager plot.PlotManager, newsManager news.NewsManager) *cache.CacheManager {
+	var backend cache.Backend
+	switch config.Cache.Backend {
+	case "none":
+		return nil
+	case "mongo":
+		var err error
+		backend, err = cache.NewBackendMongo(config.DBConfig.Name, config.Cache.Collection, config.DBConfig.DBserver)
+		if err != nil {
+			log.Print(err)
+			return nil
+		}
+	default:
+		backend = cache.NewBackendLRU(config.Cache.Size)
+	}
+	ttl := cache.TTL{
+		News:     time.Duration(config.Cache.NewsTTL) * time.Second,
+		Intraday: time.Duration(config.Cache.IntradayTTL) * time.Second,
+	}
+	return cache.NewCacheManager(plotManager, newsManager, backend, ttl)
+}
+
 // Главная структура программы включающая в себя интерфейсы основных модулей(менеджеров)
+// и пул ключей Alpha Vantage для административного просмотра их использования,
+// если ValidateSymbols включен символы проверяются через SymbolSearcher перед обращением к поставщикам данных,
+// Currencies запоминает валюты акций найденные поиском для конвертации графиков,
+// AdminToken защищает административные запросы, если он пустой они принимаются только с localhost,
+// QuoteBatchLimit ограничивает пакетный запрос котировок числом запросов к Alpha Vantage в минуту (0 - без ограничения)
 type InvestmentServer struct {
-	NewsManager news.NewsManager
-	PlotManager plot.PlotManager
-	DBManager   db.DBManager
+	NewsManager         news.NewsManager
+	PlotManager         plot.PlotManager
+	DBManager           db.DBManager
+	PortfolioManager    db.PortfolioManager
+	QuoteManager        quote.QuoteManager
+	FundamentalsManager fundamentals.FundamentalsManager
+	CalendarManager     calendar.CalendarManager
+	RateSource          fx.RateSource
+	SymbolSearcher      search.SymbolSearcher
+	ValidateSymbols     bool
+	Currencies          *search.CurrencyCache
+	KeyPool             *alphavantage.KeyPool
+	AdminToken          string
+	QuoteBatchLimit     int
 }
 
 func NewInvestmentServer(newsManager news.NewsManager, plotManager plot.PlotManager, dbManager db.DBManager) InvestmentServer {
-	return InvestmentServer{newsManager, plotManager, dbManager}
+	return InvestmentServer{NewsManager: newsManager, PlotManager: plotManager, DBManager: dbManager}
 }
 
+// Максимальное количество символов в пакетном запросе котировок, уменьшается до QuoteBatchLimit сервера
+const maxQuoteSymbols = 50
+
+// Сколько котировок пакетного запроса запрашивается одновременно
+const quoteWorkers = 4
+
+// Максимальное количество символов в запросе сравнения графиков
+const maxCompareSymbols = 10
+
 // Метод обрабатывающий запросы на получение новостей, вызывает внутри себя метод GetNews и отправляет полученый список новостей в виде Json
 func (server *InvestmentServer) NewsHandler(r *http.Request, w http.ResponseWriter) {
 	symbol := r.URL.Query()["symbol"][0]
+	if !server.checkSymbol(symbol, r, w) {
+		return
+	}
 	newsSLice, err := server.NewsManager.GetNews(symbol)
 	if err != nil {
 		server.ErrorHandler(http.StatusInternalServerError, r, w)
 		return
 	}
+	server.JSONHandler(newsSLice, r, w)
+}
+
+// Метод обрабатывающий запросы на получение графика, вызывает внутри себя метод GetPlot и отправляет полученый список свечей в виде Json
+// (необязательные параметры interval: 1min, 5min, 15min, 30min, 60min, daily, weekly, monthly; from и to в формате yyyy-mm-dd; adjusted;
+// assetClass: equity, fx, crypto - валютные пары задаются символом вида EUR/USD, криптовалюты символом BTC/EUR (класс определяется
+// по известным криптовалютам) или BTC с assetClass=crypto;
+// currency - код валюты в которую пересчитываются цены по дневным курсам)
+func (server *InvestmentServer) PlotHandler(r *http.Request, w http.ResponseWriter) {
+	symbol := r.URL.Query()["symbol"][0]
+	options, err := parsePlotOptions(r)
+	if err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	currency := ""
+	if currencyS := r.URL.Query().Get("currency"); currencyS != "" {
+		currency, err = fx.ParseCurrency(currencyS)
+		if err != nil || server.RateSource == nil {
+			server.ErrorHandler(http.StatusBadRequest, r, w)
+			return
+		}
+	}
+	if !server.checkSymbol(symbol, r, w) {
+		return
+	}
+	plotSlice, err := server.getPlot(symbol, options, w)
+	if err != nil {
+		server.PlotErrorHandler(err, r, w)
+		return
+	}
+	if currency != "" {
+		plotSlice, err = fx.Convert(plotSlice, server.RateSource, server.symbolCurrency(symbol, options), currency)
+		if err != nil {
+			server.PlotErrorHandler(err, r, w)
+			return
+		}
+		w.Header().Set("X-Currency", currency)
+	}
+	server.JSONHandler(plotSlice, r, w)
+}
+
+// Метод обрабатывающий запросы на получение графика в виде SVG изображения, параметры как у PlotImageHandler
+func (server *InvestmentServer) PlotSVGHandler(r *http.Request, w http.ResponseWriter) {
+	server.PlotImageHandler("image/svg+xml", render.SVG, r, w)
+}
+
+// Метод обрабатывающий запросы на получение графика в виде PNG изображения, параметры как у PlotImageHandler
+func (server *InvestmentServer) PlotPNGHandler(r *http.Request, w http.ResponseWriter) {
+	server.PlotImageHandler("image/png", render.PNG, r, w)
+}
+
+// Метод получающий свечи через GetPlot и отправляющий нарисованный функцией draw свечной график
+// (необязательные параметры width, height, theme: light или dark, volume: true или false, overlays: список индикаторов
+// sma, ema, bollinger, vwap через запятую с периодом через двоеточие, например sma:50; параметры графика как у PlotHandler)
+func (server *InvestmentServer) PlotImageHandler(contentType string, draw func(io.Writer, []plot.Candle, render.Options) error, r *http.Request, w http.ResponseWriter) {
+	symbol := r.URL.Query().Get("symbol")
+	options, err := parsePlotOptions(r)
+	if symbol == "" || err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	renderOptions, err := parseRenderOptions(r)
+	if err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	overlays, err := parseOverlays(r.URL.Query().Get("overlays"))
+	if err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	if !server.checkSymbol(symbol, r, w) {
+		return
+	}
+	plotSlice, err := server.getPlot(symbol, options, w)
+	if err != nil {
+		server.PlotErrorHandler(err, r, w)
+		return
+	}
+	for _, overlay := range overlays {
+		lines, err := render.CalculateOverlays(overlay.name, plotSlice, overlay.period)
+		if err != nil {
+			server.ErrorHandler(http.StatusBadRequest, r, w)
+			return
+		}
+		renderOptions.Overlays = append(renderOptions.Overlays, lines...)
+	}
+	renderOptions.Title = symbol
+	image := &bytes.Buffer{}
+	if err := draw(image, plotSlice, renderOptions); err != nil {
+		server.ErrorHandler(http.StatusInternalServerError, r, w)
+		return
+	}
 	pageServer := ""
 	if origin, ok := r.Header["Origin"]; ok {
 		pageServer = origin[0]
 	}
 	w.Header().Set("Access-Control-Allow-Origin", pageServer)
-	jsonData, err := json.Marshal(newsSLice)
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, err = image.WriteTo(w)
 	if err != nil {
-		server.ErrorHandler(http.StatusInternalServerError, r, w)
+		log.Print(err)
+	}
+}
+
+// Метод обрабатывающий запросы на расчет технического индикатора, получает свечи через GetPlot и отправляет значения индикатора в виде Json
+// (параметры name: sma, ema, rsi, macd, bollinger, atr, obv, vwap; необязательные period и параметры графика как у PlotHandler)
+func (server *InvestmentServer) IndicatorsHandler(r *http.Request, w http.ResponseWriter) {
+	symbol := r.URL.Query().Get("symbol")
+	name := r.URL.Query().Get("name")
+	options, err := parsePlotOptions(r)
+	if symbol == "" || name == "" || err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_, err = w.Write(jsonData)
+	period := 0
+	if periodS := r.URL.Query().Get("period"); periodS != "" {
+		period, err = strconv.Atoi(periodS)
+		if err != nil {
+			server.ErrorHandler(http.StatusBadRequest, r, w)
+			return
+		}
+	}
+	if !server.checkSymbol(symbol, r, w) {
+		return
+	}
+	plotSlice, err := server.getPlot(symbol, options, w)
 	if err != nil {
-		server.ErrorHandler(http.StatusInternalServerError, r, w)
+		server.PlotErrorHandler(err, r, w)
 		return
 	}
+	values, err := indicators.Calculate(name, plotSlice, period)
+	if err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	server.JSONHandler(values, r, w)
 }
 
-// Метод обрабатывающий запросы на получение графика, вызывает внутри себя метод GetPlot и отправляет полученый список свечей в виде Json
-func (server *InvestmentServer) PlotHandler(r *http.Request, w http.ResponseWriter) {
-	symbol := r.URL.Query()["symbol"][0]
-	plotSlice, err := server.PlotManager.GetPlot(symbol)
+// Метод обрабатывающий запросы на поиск свечных моделей, получает свечи через GetPlot и отправляет найденные модели в виде Json
+// (необязательные параметры графика как у PlotHandler; Index и Date модели указывают на ее последнюю свечу в графике с теми же параметрами)
+func (server *InvestmentServer) PatternsHandler(r *http.Request, w http.ResponseWriter) {
+	symbol := r.URL.Query().Get("symbol")
+	options, err := parsePlotOptions(r)
+	if symbol == "" || err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	if !server.checkSymbol(symbol, r, w) {
+		return
+	}
+	plotSlice, err := server.getPlot(symbol, options, w)
+	if err != nil {
+		server.PlotErrorHandler(err, r, w)
+		return
+	}
+	server.JSONHandler(patterns.Detect(plotSlice), r, w)
+}
+
+// Метод обрабатывающий запросы на агрегацию графика, получает свечи через GetPlot и отправляет свечи укрупненного периода в виде Json
+// (параметр period: weekly, monthly, quarterly или <N>d; необязательные параметры графика как у PlotHandler)
+func (server *InvestmentServer) ResampleHandler(r *http.Request, w http.ResponseWriter) {
+	symbol := r.URL.Query().Get("symbol")
+	period, err := plot.ParsePeriod(r.URL.Query().Get("period"))
+	if symbol == "" || err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	options, err := parsePlotOptions(r)
+	if err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	if !server.checkSymbol(symbol, r, w) {
+		return
+	}
+	plotSlice, err := server.getPlot(symbol, options, w)
+	if err != nil {
+		server.PlotErrorHandler(err, r, w)
+		return
+	}
+	resampled, err := plot.Resample(plotSlice, period)
 	if err != nil {
 		server.ErrorHandler(http.StatusInternalServerError, r, w)
 		return
 	}
-	pageServer := ""
-	if origin, ok := r.Header["Origin"]; ok {
-		pageServer = origin[0]
+	server.JSONHandler(resampled, r, w)
+}
+
+// Метод обрабатывающий запросы на получение текущей котировки, вызывает внутри себя метод GetQuote и отправляет котировку в виде Json
+// (параметр symbol для одного символа или symbols со списком символов через запятую, для списка отправляются результаты
+// по каждому символу в том же порядке, ошибка одного символа не мешает получить остальные; символов в списке не больше
+// чем запросов к Alpha Vantage в минуту, но квота общая с другими запросами, поэтому результат может быть частичным -
+// у символов не уложившихся в квоту будет ошибка квоты)
+func (server *InvestmentServer) QuoteHandler(r *http.Request, w http.ResponseWriter) {
+	if server.QuoteManager == nil {
+		server.ErrorHandler(http.StatusNotFound, r, w)
+		return
 	}
-	w.Header().Set("Access-Control-Allow-Origin", pageServer)
-	jsonData, err := json.Marshal(plotSlice)
+	if symbolsS := r.URL.Query().Get("symbols"); symbolsS != "" {
+		symbols := parseSymbolList(symbolsS)
+		limit := maxQuoteSymbols
+		if server.QuoteBatchLimit > 0 && server.QuoteBatchLimit < limit {
+			limit = server.QuoteBatchLimit
+		}
+		if len(symbols) == 0 || len(symbols) > limit {
+			server.ErrorHandler(http.StatusBadRequest, r, w)
+			return
+		}
+		server.JSONHandler(quote.GetQuotes(server.QuoteManager, symbols, quoteWorkers), r, w)
+		return
+	}
+	symbol := r.URL.Query().Get("symbol")
+	if symbol == "" {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	if !server.checkSymbol(symbol, r, w) {
+		return
+	}
+	quoteData, err := server.QuoteManager.GetQuote(symbol)
 	if err != nil {
+		server.PlotErrorHandler(err, r, w)
+		return
+	}
+	server.JSONHandler(quoteData, r, w)
+}
+
+// Метод обрабатывающий запросы на сравнение графиков нескольких символов, получает графики параллельно через GetPlot,
+// оставляет только общие даты, нормализует графики к 100 на первую общую дату и отправляет их в виде Json вместе с доходностью за период
+// (параметр symbols со списком символов через запятую; необязательные параметры графика как у PlotHandler)
+func (server *InvestmentServer) CompareHandler(r *http.Request, w http.ResponseWriter) {
+	symbols := parseSymbolList(r.URL.Query().Get("symbols"))
+	if len(symbols) == 0 || len(symbols) > maxCompareSymbols {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	options, err := parsePlotOptions(r)
+	if err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	// класс актива определяется по каждому символу отдельно, чтобы можно было сравнивать акции с валютными парами
+	options.AssetClass, _ = plot.ParseAssetClass(r.URL.Query().Get("assetClass"))
+	for _, symbol := range symbols {
+		if !server.checkSymbol(symbol, r, w) {
+			return
+		}
+	}
+	plots, err := plot.GetPlots(server.PlotManager, symbols, options)
+	if err != nil {
+		server.PlotErrorHandler(err, r, w)
+		return
+	}
+	server.JSONHandler(plot.Compare(symbols, plots, 100), r, w)
+}
+
+// Метод обрабатывающий запросы на расчет статистики доходности и риска, получает графики символа и бенчмарка через GetPlot
+// и отправляет статистику в виде Json (необязательные параметры benchmark - символ для расчета беты; riskFree - годовая
+// безрисковая ставка, например 0.02; параметры графика как у PlotHandler, для акций стоит передавать adjusted=true)
+func (server *InvestmentServer) StatsHandler(r *http.Request, w http.ResponseWriter) {
+	symbol := r.URL.Query().Get("symbol")
+	benchmark := r.URL.Query().Get("benchmark")
+	options, err := parsePlotOptions(r)
+	if symbol == "" || err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	riskFree := 0.0
+	if riskFreeS := r.URL.Query().Get("riskFree"); riskFreeS != "" {
+		riskFree, err = strconv.ParseFloat(riskFreeS, 64)
+		if err != nil {
+			server.ErrorHandler(http.StatusBadRequest, r, w)
+			return
+		}
+	}
+	symbols := []string{symbol}
+	if benchmark != "" {
+		symbols = append(symbols, benchmark)
+	}
+	for _, symbol := range symbols {
+		if !server.checkSymbol(symbol, r, w) {
+			return
+		}
+	}
+	periodsPerYear := analytics.PeriodsPerYear(options.Interval, options.AssetClass)
+	// класс актива бенчмарка определяется по его собственному символу
+	options.AssetClass, _ = plot.ParseAssetClass(r.URL.Query().Get("assetClass"))
+	plots, err := plot.GetPlots(server.PlotManager, symbols, options)
+	if err != nil {
+		server.PlotErrorHandler(err, r, w)
+		return
+	}
+	var benchmarkPlot []plot.Candle
+	if benchmark != "" {
+		benchmarkPlot = plots[1]
+	}
+	stats, err := analytics.Calculate(plots[0], benchmarkPlot, periodsPerYear, riskFree)
+	if err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	stats.Symbol, stats.Benchmark = symbol, benchmark
+	server.JSONHandler(stats, r, w)
+}
+
+// Структура backtestRequest содержит тело запроса на симуляцию стратегии: описание встроенной стратегии
+// и параметры сделок InitialCapital, Commission, Slippage
+type backtestRequest struct {
+	Strategy backtest.StrategySpec
+	backtest.Config
+}
+
+// Максимальный размер тела POST запроса в байтах
+const maxRequestBody = 1 << 16
+
+// Метод обрабатывающий POST запросы на симуляцию торговой стратегии, получает свечи через GetPlot и отправляет результат
+// в виде Json (тело запроса - Json вида {"Strategy": {"Type": "smaCross", "Fast": 10, "Slow": 30}, "Commission": 0.001},
+// символ и параметры графика передаются в строке запроса как у PlotHandler, для акций стоит передавать adjusted=true)
+func (server *InvestmentServer) BacktestHandler(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodPost {
+		server.ErrorHandler(http.StatusMethodNotAllowed, r, w)
+		return
+	}
+	symbol := r.URL.Query().Get("symbol")
+	options, err := parsePlotOptions(r)
+	if symbol == "" || err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	request := backtestRequest{}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	strategy, err := backtest.NewStrategy(request.Strategy)
+	if err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	if !server.checkSymbol(symbol, r, w) {
+		return
+	}
+	plotSlice, err := server.getPlot(symbol, options, w)
+	if err != nil {
+		server.PlotErrorHandler(err, r, w)
+		return
+	}
+	result, err := backtest.Run(plotSlice, strategy, request.Config)
+	if err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	result.Symbol = symbol
+	server.JSONHandler(result, r, w)
+}
+
+// Структура transactionRequest содержит тело запроса на запись операции портфеля, дата в формате yyyy-mm-dd
+// (пустая дата - сегодня), тип buy или sell
+type transactionRequest struct {
+	Symbol   string
+	Type     string
+	Date     string
+	Quantity float64
+	Price    float64
+	Fees     float64
+}
+
+// Метод обрабатывающий запросы к операциям портфеля пользователя (параметр user): GET отправляет список операций в виде Json,
+// POST записывает операцию из тела запроса вида {"Symbol": "IBM", "Type": "buy", "Date": "2020-01-02", "Quantity": 10, "Price": 120, "Fees": 1}
+// и отправляет ее в виде Json; продажа большего количества чем есть в портфеле на дату продажи отклоняется
+func (server *InvestmentServer) PortfolioTransactionsHandler(r *http.Request, w http.ResponseWriter) {
+	if server.PortfolioManager == nil {
+		server.ErrorHandler(http.StatusNotFound, r, w)
+		return
+	}
+	user := r.URL.Query().Get("user")
+	if user == "" {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		transactions, err := server.PortfolioManager.GetTransactions(user)
+		if err != nil {
+			log.Print(err)
+			server.ErrorHandler(http.StatusInternalServerError, r, w)
+			return
+		}
+		server.JSONHandler(transactions, r, w)
+	case http.MethodPost:
+		transaction, err := parseTransaction(user, r, w)
+		if err != nil {
+			server.ErrorHandler(http.StatusBadRequest, r, w)
+			return
+		}
+		if !server.checkSymbol(transaction.Symbol, r, w) {
+			return
+		}
+		transactions, err := server.PortfolioManager.GetTransactions(user)
+		if err != nil {
+			log.Print(err)
+			server.ErrorHandler(http.StatusInternalServerError, r, w)
+			return
+		}
+		if _, err := portfolio.Positions(append(transactions, transaction), portfolio.MethodFIFO); err != nil {
+			server.ErrorHandler(http.StatusBadRequest, r, w)
+			return
+		}
+		if err := server.PortfolioManager.AddTransaction(transaction); err != nil {
+			log.Print(err)
+			server.ErrorHandler(http.StatusInternalServerError, r, w)
+			return
+		}
+		server.JSONHandler(transaction, r, w)
+	default:
+		server.ErrorHandler(http.StatusMethodNotAllowed, r, w)
+	}
+}
+
+// Вспомогательный метод считывающий операцию портфеля из тела запроса и проверяющий ее
+func parseTransaction(user string, r *http.Request, w http.ResponseWriter) (db.Transaction, error) {
+	request := transactionRequest{}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&request); err != nil {
+		return db.Transaction{}, err
+	}
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+	if request.Date != "" {
+		var err error
+		date, err = time.Parse("2006-01-02", request.Date)
+		if err != nil {
+			return db.Transaction{}, err
+		}
+	}
+	transaction := db.Transaction{
+		UserID:   user,
+		Symbol:   strings.ToUpper(strings.TrimSpace(request.Symbol)),
+		Type:     strings.ToLower(request.Type),
+		Date:     date,
+		Quantity: request.Quantity,
+		Price:    request.Price,
+		Fees:     request.Fees,
+	}
+	return transaction, portfolio.Validate(transaction)
+}
+
+// Метод обрабатывающий запросы на расчет позиций портфеля пользователя (параметр user), считает позиции по операциям
+// из PortfolioManager, оценивает их по последним ценам закрытия через PlotManager и отправляет итоги в виде Json
+// (необязательный параметр method: fifo или average - способ расчета себестоимости)
+func (server *InvestmentServer) PortfolioPositionsHandler(r *http.Request, w http.ResponseWriter) {
+	if server.PortfolioManager == nil {
+		server.ErrorHandler(http.StatusNotFound, r, w)
+		return
+	}
+	user := r.URL.Query().Get("user")
+	method, err := portfolio.ParseMethod(r.URL.Query().Get("method"))
+	if user == "" || err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	transactions, err := server.PortfolioManager.GetTransactions(user)
+	if err != nil {
+		log.Print(err)
 		server.ErrorHandler(http.StatusInternalServerError, r, w)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_, err = w.Write(jsonData)
+	positions, err := portfolio.Positions(transactions, method)
 	if err != nil {
+		log.Print(err)
 		server.ErrorHandler(http.StatusInternalServerError, r, w)
 		return
 	}
+	summary, err := portfolio.Value(positions, method, server.PlotManager, time.Now())
+	if err != nil {
+		server.PlotErrorHandler(err, r, w)
+		return
+	}
+	server.JSONHandler(summary, r, w)
+}
+
+// Метод обрабатывающий запросы на расчет динамики портфеля пользователя (параметр user), получает дневные графики символов
+// портфеля через GetPlot и отправляет стоимость портфеля, индекс доходности и доходности взвешенные по времени и по деньгам
+// в виде Json (необязательный параметр benchmark - символ для сравнения на тех же датах, например SPY)
+func (server *InvestmentServer) PortfolioPerformanceHandler(r *http.Request, w http.ResponseWriter) {
+	if server.PortfolioManager == nil {
+		server.ErrorHandler(http.StatusNotFound, r, w)
+		return
+	}
+	user := r.URL.Query().Get("user")
+	benchmark := r.URL.Query().Get("benchmark")
+	if user == "" {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	if benchmark != "" && !server.checkSymbol(benchmark, r, w) {
+		return
+	}
+	transactions, err := server.PortfolioManager.GetTransactions(user)
+	if err != nil {
+		log.Print(err)
+		server.ErrorHandler(http.StatusInternalServerError, r, w)
+		return
+	}
+	if len(transactions) == 0 {
+		server.ErrorHandler(http.StatusNotFound, r, w)
+		return
+	}
+	performance, err := portfolio.GetPerformance(transactions, server.PlotManager, benchmark)
+	if err != nil {
+		server.PlotErrorHandler(err, r, w)
+		return
+	}
+	server.JSONHandler(performance, r, w)
+}
+
+// Метод обрабатывающий запросы на получение фундаментальных данных компании, вызывает внутри себя метод GetOverview
+// или GetStatements и отправляет результат в виде Json (необязательный параметр statement: overview, income, balance, cashflow)
+func (server *InvestmentServer) FundamentalsHandler(r *http.Request, w http.ResponseWriter) {
+	if server.FundamentalsManager == nil {
+		server.ErrorHandler(http.StatusNotFound, r, w)
+		return
+	}
+	symbol := r.URL.Query().Get("symbol")
+	statement, err := fundamentals.ParseStatement(r.URL.Query().Get("statement"))
+	if symbol == "" || err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	if !server.checkSymbol(symbol, r, w) {
+		return
+	}
+	var data interface{}
+	if statement == fundamentals.StatementOverview {
+		data, err = server.FundamentalsManager.GetOverview(symbol)
+	} else {
+		data, err = server.FundamentalsManager.GetStatements(symbol, statement)
+	}
+	if err != nil {
+		server.PlotErrorHandler(err, r, w)
+		return
+	}
+	server.JSONHandler(data, r, w)
+}
+
+// Метод обрабатывающий запросы на получение календаря событий, вызывает внутри себя метод GetEvents и отправляет события в виде Json
+// (необязательные параметры kind: earnings, dividends - по умолчанию все события; from и to в формате yyyy-mm-dd),
+// поле CandleDate событий совпадает с Date дневной свечи из PlotHandler на которой событие отражается в цене
+func (server *InvestmentServer) CalendarHandler(r *http.Request, w http.ResponseWriter) {
+	if server.CalendarManager == nil {
+		server.ErrorHandler(http.StatusNotFound, r, w)
+		return
+	}
+	symbol := r.URL.Query().Get("symbol")
+	kind, err := calendar.ParseKind(r.URL.Query().Get("kind"))
+	if symbol == "" || err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	if !server.checkSymbol(symbol, r, w) {
+		return
+	}
+	events, err := server.CalendarManager.GetEvents(symbol, kind)
+	if err != nil {
+		server.PlotErrorHandler(err, r, w)
+		return
+	}
+	filtered := []calendar.Event{}
+	for _, event := range events {
+		if (from.IsZero() || !event.Date.Before(from)) && (to.IsZero() || !event.Date.After(to)) {
+			filtered = append(filtered, event)
+		}
+	}
+	server.JSONHandler(filtered, r, w)
+}
+
+// Метод обрабатывающий запросы на поиск финансовых активов по части символа или названия компании,
+// вызывает внутри себя метод Search и отправляет найденные активы в виде Json (параметр q)
+func (server *InvestmentServer) SearchHandler(r *http.Request, w http.ResponseWriter) {
+	if server.SymbolSearcher == nil {
+		server.ErrorHandler(http.StatusNotFound, r, w)
+		return
+	}
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		server.ErrorHandler(http.StatusBadRequest, r, w)
+		return
+	}
+	matches, err := server.SymbolSearcher.Search(query)
+	if err != nil {
+		server.PlotErrorHandler(err, r, w)
+		return
+	}
+	server.JSONHandler(matches, r, w)
+}
+
+// Метод обрабатывающий административный запрос статистики использования ключей Alpha Vantage, отправляет ее в виде Json,
+// запрос должен пройти проверку authorizeAdmin, заголовок CORS не отправляется чтобы страницы других сайтов не могли прочитать ответ
+func (server *InvestmentServer) KeysHandler(r *http.Request, w http.ResponseWriter) {
+	if status := server.authorizeAdmin(r); status != http.StatusOK {
+		w.WriteHeader(status)
+		return
+	}
+	if server.KeyPool == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	server.writeJSON(server.KeyPool.Usage(), w)
+}
+
+// Вспомогательный метод проверяющий административный запрос: если AdminToken задан, он должен быть передан в заголовке
+// Authorization: Bearer (иначе 401), если не задан - запрос должен прийти с localhost (иначе 403); возвращает 200 если запрос разрешен
+func (server *InvestmentServer) authorizeAdmin(r *http.Request) int {
+	if server.AdminToken == "" {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if ip := net.ParseIP(host); err != nil || ip == nil || !ip.IsLoopback() {
+			return http.StatusForbidden
+		}
+		return http.StatusOK
+	}
+	token := r.Header.Get("Authorization")
+	if !strings.HasPrefix(token, "Bearer ") || subtle.ConstantTimeCompare([]byte(token[len("Bearer "):]), []byte(server.AdminToken)) != 1 {
+		return http.StatusUnauthorized
+	}
+	return http.StatusOK
 }
 
 // Метод обрабатывающий запросы на работу с базой данных, вызывает внутри себя метод AddHistory и отправляет статус 200
@@ -122,6 +879,246 @@ func (server *InvestmentServer) DBHandler(r *http.Request, w http.ResponseWriter
 	w.WriteHeader(http.StatusOK)
 }
 
+// Вспомогательный метод проверяющий символ через SymbolSearcher если ValidateSymbols включен,
+// если символ не найден отправляет 404 и возвращает false; если поиск недоступен символ считается верным,
+// валютные пары и криптовалюты не проверяются т.к. поиск находит только акции и фонды
+func (server *InvestmentServer) checkSymbol(symbol string, r *http.Request, w http.ResponseWriter) bool {
+	if !server.ValidateSymbols || server.SymbolSearcher == nil {
+		return true
+	}
+	assetClass, _ := plot.ParseAssetClass(r.URL.Query().Get("assetClass"))
+	if plot.ResolveAssetClass(symbol, assetClass) != plot.AssetClassEquity {
+		return true
+	}
+	ok, err := search.Validate(server.SymbolSearcher, symbol)
+	if err != nil {
+		log.Print(err)
+		return true
+	}
+	if !ok {
+		server.ErrorHandler(http.StatusNotFound, r, w)
+	}
+	return ok
+}
+
+// Вспомогательный метод определяющий валюту в которой торгуется актив: у валютных пар и криптовалют это вторая валюта символа,
+// у акций валюта запомненная Currencies (поиск выполняется один раз на символ), иначе USD без обращения к поиску
+func (server *InvestmentServer) symbolCurrency(symbol string, options plot.PlotOptions) string {
+	if options.AssetClass != plot.AssetClassEquity {
+		_, currency, err := plot.SplitPair(symbol, options.AssetClass)
+		if err == nil {
+			return currency
+		}
+	}
+	if server.Currencies != nil {
+		currency, err := server.Currencies.Currency(symbol)
+		if err != nil {
+			log.Print(err)
+		}
+		if currency != "" {
+			return currency
+		}
+	}
+	return "USD"
+}
+
+// Вспомогательный метод получающий свечи через PlotManager, если PlotManager сообщает поставщика данных,
+// его название записывается в заголовок ответа X-Data-Provider
+func (server *InvestmentServer) getPlot(symbol string, options plot.PlotOptions, w http.ResponseWriter) ([]plot.Candle, error) {
+	sourced, ok := server.PlotManager.(plot.SourcedPlotManager)
+	if !ok {
+		return server.PlotManager.GetPlot(symbol, options)
+	}
+	plotSlice, source, err := sourced.GetPlotWithSource(symbol, options)
+	if err != nil {
+		return nil, err
+	}
+	if source != "" {
+		w.Header().Set("X-Data-Provider", source)
+	}
+	return plotSlice, nil
+}
+
+// Метод отправляющий данные в виде Json со статусом 200, используется в остальных Handler-ах
+func (server *InvestmentServer) JSONHandler(data interface{}, r *http.Request, w http.ResponseWriter) {
+	pageServer := ""
+	if origin, ok := r.Header["Origin"]; ok {
+		pageServer = origin[0]
+	}
+	w.Header().Set("Access-Control-Allow-Origin", pageServer)
+	server.writeJSON(data, w)
+}
+
+// Вспомогательный метод отправляющий данные в виде Json со статусом 200 без заголовка CORS
+func (server *InvestmentServer) writeJSON(data interface{}, w http.ResponseWriter) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(jsonData)
+	if err != nil {
+		log.Print(err)
+	}
+}
+
+// Вспомогательный метод разбивающий список символов через запятую, пустые элементы пропускаются
+func parseSymbolList(s string) []string {
+	symbols := []string{}
+	for _, symbol := range strings.Split(s, ",") {
+		if symbol = strings.TrimSpace(symbol); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
+
+// Вспомогательный метод считывающий из запроса необязательные параметры графика interval, from, to, adjusted и assetClass,
+// класс актива определяется по символу если не указан явно
+func parsePlotOptions(r *http.Request) (plot.PlotOptions, error) {
+	options := plot.PlotOptions{}
+	var err error
+	options.Interval, err = plot.ParseInterval(r.URL.Query().Get("interval"))
+	if err != nil {
+		return options, err
+	}
+	options.From, options.To, err = parseDateRange(r)
+	if err != nil {
+		return options, err
+	}
+	if adjustedS := r.URL.Query().Get("adjusted"); adjustedS != "" {
+		options.Adjusted, err = strconv.ParseBool(adjustedS)
+		if err != nil {
+			return options, err
+		}
+	}
+	if options.Adjusted && options.Interval.IsIntraday() {
+		return options, errors.New("adjustedIntraday")
+	}
+	assetClass, err := plot.ParseAssetClass(r.URL.Query().Get("assetClass"))
+	if err != nil {
+		return options, err
+	}
+	options.AssetClass = plot.ResolveAssetClass(r.URL.Query().Get("symbol"), assetClass)
+	if options.Adjusted && options.AssetClass != plot.AssetClassEquity {
+		return options, errors.New("adjustedNotSupported")
+	}
+	return options, nil
+}
+
+// Вспомогательный метод считывающий из запроса необязательные параметры изображения width, height, theme и volume,
+// объем торгов рисуется по умолчанию
+func parseRenderOptions(r *http.Request) (render.Options, error) {
+	options := render.Options{Volume: true}
+	var err error
+	if widthS := r.URL.Query().Get("width"); widthS != "" {
+		options.Width, err = strconv.Atoi(widthS)
+		if err != nil {
+			return options, err
+		}
+	}
+	if heightS := r.URL.Query().Get("height"); heightS != "" {
+		options.Height, err = strconv.Atoi(heightS)
+		if err != nil {
+			return options, err
+		}
+	}
+	if options.Width < 0 || options.Width > render.MaxWidth || options.Height < 0 || options.Height > render.MaxHeight ||
+		(options.Width != 0 && options.Width < render.MinWidth) || (options.Height != 0 && options.Height < render.MinHeight) {
+		return options, errors.New("wrongSize")
+	}
+	options.Theme, err = render.ParseTheme(r.URL.Query().Get("theme"))
+	if err != nil {
+		return options, err
+	}
+	if volumeS := r.URL.Query().Get("volume"); volumeS != "" {
+		options.Volume, err = strconv.ParseBool(volumeS)
+		if err != nil {
+			return options, err
+		}
+	}
+	return options, nil
+}
+
+// Структура overlayParam содержит название и период индикатора, который накладывается на изображение графика
+type overlayParam struct {
+	name   string
+	period int
+}
+
+// Максимальное количество индикаторов на одном изображении графика
+const maxOverlays = 5
+
+// Вспомогательный метод разбирающий список индикаторов вида name[:period] через запятую, период 0 - период по умолчанию
+func parseOverlays(s string) ([]overlayParam, error) {
+	overlays := []overlayParam{}
+	for _, item := range parseSymbolList(s) {
+		overlay := overlayParam{name: item}
+		if i := strings.Index(item, ":"); i >= 0 {
+			period, err := strconv.Atoi(item[i+1:])
+			if err != nil || period <= 0 {
+				return nil, errors.New("wrongOverlay")
+			}
+			overlay = overlayParam{name: item[:i], period: period}
+		}
+		overlays = append(overlays, overlay)
+	}
+	if len(overlays) > maxOverlays {
+		return nil, errors.New("tooManyOverlays")
+	}
+	return overlays, nil
+}
+
+// Вспомогательный метод считывающий из запроса необязательные параметры from и to в формате yyyy-mm-dd,
+// to включает в себя весь указанный день
+func parseDateRange(r *http.Request) (time.Time, time.Time, error) {
+	var from, to time.Time
+	var err error
+	if fromS := r.URL.Query().Get("from"); fromS != "" {
+		from, err = time.Parse("2006-01-02", fromS)
+		if err != nil {
+			return from, to, err
+		}
+	}
+	if toS := r.URL.Query().Get("to"); toS != "" {
+		to, err = time.Parse("2006-01-02", toS)
+		if err != nil {
+			return from, to, err
+		}
+		to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return from, to, errors.New("wrongDateRange")
+	}
+	return from, to, nil
+}
+
+// Метод срабатывающий в случае ошибки получения данных от Alpha Vantage, выбирает статус ответа по ошибке:
+// 429 если исчерпан лимит запросов (с заголовком Retry-After если известно когда повторить), 404 если символ не найден,
+// 503 если ключ API неверный, 502 если ответ не удалось разобрать, иначе 500
+func (server *InvestmentServer) PlotErrorHandler(err error, r *http.Request, w http.ResponseWriter) {
+	log.Print(err)
+	var quotaErr *alphavantage.QuotaError
+	switch {
+	case errors.As(err, &quotaErr):
+		retryAfter := int(math.Ceil(quotaErr.RetryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		server.ErrorHandler(http.StatusTooManyRequests, r, w)
+	case errors.Is(err, alphavantage.ErrRateLimited):
+		server.ErrorHandler(http.StatusTooManyRequests, r, w)
+	case errors.Is(err, alphavantage.ErrUnknownSymbol):
+		server.ErrorHandler(http.StatusNotFound, r, w)
+	case errors.Is(err, alphavantage.ErrInvalidKey):
+		server.ErrorHandler(http.StatusServiceUnavailable, r, w)
+	case errors.Is(err, alphavantage.ErrMalformedResponse):
+		server.ErrorHandler(http.StatusBadGateway, r, w)
+	default:
+		server.ErrorHandler(http.StatusInternalServerError, r, w)
+	}
+}
+
 // Метод срабатывающий в случае неправильного запроса со стороны сайта или возникновения ошибки во время обработки запроса,
 // используется в остальных Handler-ах
 func (server *InvestmentServer) ErrorHandler(httpStatus int, r *http.Request, w http.ResponseWriter) {
@@ -134,10 +1131,41 @@ func (server *InvestmentServer) ErrorHandler(httpStatus int, r *http.Request, w
 }
 
 // Создаем экземпляры реализаций интерфейсов сервера, затем создаем экземпляр самого сервера с этими реализациями
-var newsManager = news.NewNewsManagerYahoo()
-var plotManager = plot.NewPlotManagerAlphaVantage(loadConfig().VentageKey)
+// (пул ключей и ограничитель частоты запросов общие для всех обращений к Alpha Vantage, лимиты умножаются на число ключей)
+var ventageKeys = newKeyPool(loadConfig())
+var ventageLimiter = alphavantage.NewRateLimiter(loadConfig().VentageLimits.PerMinute*ventageKeys.Len(),
+	loadConfig().VentageLimits.PerDay*ventageKeys.Len(), time.Duration(loadConfig().VentageLimits.MaxWait)*time.Second)
+var httpClient = newHTTPClient(loadConfig())
+var ventageClient = alphavantage.NewClient(loadConfig().VentageURL, httpClient, ventageKeys, ventageLimiter)
+var newsManager = news.NewNewsManagerYahooWithClient(loadConfig().YahooURL, httpClient)
+var quoteManager = quote.NewQuoteManagerAlphaVantage(ventageClient)
+var fundamentalsManager = fundamentals.NewFundamentalsManagerAlphaVantage(ventageClient)
+var calendarManager = calendar.NewCalendarManagerAlphaVantage(ventageClient)
+var symbolSearcher = newSymbolSearcher(loadConfig())
+var plotManager = newPlotManager(loadConfig())
 var dbManager = db.NewDBManagerMongo(loadConfig().DBConfig.Name, loadConfig().DBConfig.Collection, loadConfig().DBConfig.DBserver)
-var server = NewInvestmentServer(newsManager, plotManager, dbManager)
+var portfolioManager = db.NewPortfolioManagerMongo(loadConfig().DBConfig.Name, loadConfig().DBConfig.Portfolio, loadConfig().DBConfig.DBserver)
+var cacheManager = newCacheManager(loadConfig(), plotManager, newsManager)
+var server = newServer()
+
+// Метод создающий экземпляр сервера, если кэш включен менеджеры графиков и новостей оборачиваются в кэширующий менеджер
+func newServer() InvestmentServer {
+	investmentServer := NewInvestmentServer(newsManager, plotManager, dbManager)
+	if cacheManager != nil {
+		investmentServer = NewInvestmentServer(cacheManager, cacheManager, dbManager)
+	}
+	investmentServer.PortfolioManager = portfolioManager
+	investmentServer.QuoteManager = quoteManager
+	investmentServer.FundamentalsManager = fundamentalsManager
+	investmentServer.CalendarManager = calendarManager
+	investmentServer.RateSource = fx.NewRateSourcePlot(investmentServer.PlotManager)
+	investmentServer.SymbolSearcher = symbolSearcher
+	investmentServer.ValidateSymbols = loadConfig().Search.Validate
+	investmentServer.Currencies = search.NewCurrencyCache(symbolSearcher)
+	investmentServer.AdminToken = loadConfig().AdminToken
+	investmentServer.QuoteBatchLimit = loadConfig().VentageLimits.PerMinute * ventageKeys.Len()
+	return investmentServer
+}
 
 // Главный обработчик, вызывается при получении запроса на сервер, решает какой из Handler-ов должен этот запрос обработать
 func mainHandler(w http.ResponseWriter, r *http.Request) {
@@ -152,6 +1180,54 @@ func mainHandler(w http.ResponseWriter, r *http.Request) {
 	case command == "/plot":
 		log.Printf("%s\n", "plot")
 		server.PlotHandler(r, w)
+	case command == "/plot.svg":
+		log.Printf("%s\n", "plot svg")
+		server.PlotSVGHandler(r, w)
+	case command == "/plot.png":
+		log.Printf("%s\n", "plot png")
+		server.PlotPNGHandler(r, w)
+	case command == "/admin/keys":
+		log.Printf("%s\n", "admin keys")
+		server.KeysHandler(r, w)
+	case command == "/resample":
+		log.Printf("%s\n", "resample")
+		server.ResampleHandler(r, w)
+	case command == "/indicators":
+		log.Printf("%s\n", "indicators")
+		server.IndicatorsHandler(r, w)
+	case command == "/quote":
+		log.Printf("%s\n", "quote")
+		server.QuoteHandler(r, w)
+	case command == "/compare":
+		log.Printf("%s\n", "compare")
+		server.CompareHandler(r, w)
+	case command == "/patterns":
+		log.Printf("%s\n", "patterns")
+		server.PatternsHandler(r, w)
+	case command == "/backtest":
+		log.Printf("%s\n", "backtest")
+		server.BacktestHandler(r, w)
+	case command == "/portfolio/transactions":
+		log.Printf("%s\n", "portfolio transactions")
+		server.PortfolioTransactionsHandler(r, w)
+	case command == "/portfolio/positions":
+		log.Printf("%s\n", "portfolio positions")
+		server.PortfolioPositionsHandler(r, w)
+	case command == "/portfolio/performance":
+		log.Printf("%s\n", "portfolio performance")
+		server.PortfolioPerformanceHandler(r, w)
+	case command == "/stats":
+		log.Printf("%s\n", "stats")
+		server.StatsHandler(r, w)
+	case command == "/fundamentals":
+		log.Printf("%s\n", "fundamentals")
+		server.FundamentalsHandler(r, w)
+	case command == "/calendar":
+		log.Printf("%s\n", "calendar")
+		server.CalendarHandler(r, w)
+	case command == "/search":
+		log.Printf("%s\n", "search")
+		server.SearchHandler(r, w)
 	default:
 		log.Printf("%s\n", "wrong command")
 		server.ErrorHandler(http.StatusBadRequest, r, w)
@@ -159,6 +1235,7 @@ func mainHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func main() {
+	server.KeyPool = ventageKeys
 	localPort := ":" + loadConfig().LocalPort
 	http.HandleFunc("/", mainHandler)
 	log.Printf("%s\n", "Server is Up")
@@ -1,13 +1,25 @@
 package main
 
 import (
+	"InvestmentHelpver_V2/internal/alphavantage"
+	"InvestmentHelpver_V2/internal/cache"
+	"InvestmentHelpver_V2/internal/calendar"
 	"InvestmentHelpver_V2/internal/db"
+	"InvestmentHelpver_V2/internal/fundamentals"
+	"InvestmentHelpver_V2/internal/fx"
 	"InvestmentHelpver_V2/internal/news"
 	"InvestmentHelpver_V2/internal/plot"
+	"InvestmentHelpver_V2/internal/plot/plottest"
+	"InvestmentHelpver_V2/internal/quote"
+	"InvestmentHelpver_V2/internal/search"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 var testSymbolReal = "IBM"
@@ -54,11 +66,61 @@ func TestPlotHandler(t *testing.T) {
 		}
 	})
 
-	t.Run("test response 500 plotManagerAlphaVentage", func(t *testing.T) {
+	t.Run("test response 400 wrong interval plotManagerAlphaVentage", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/plot?symbol=%s&interval=2min", testSymbolReal), nil)
+		response := httptest.NewRecorder()
+		serverAlphaVentage.PlotHandler(request, response)
+		wantCode := 400
+		if response.Code != wantCode {
+			t.Error(fmt.Sprintf("wrong response code, want %d, get %d", wantCode, response.Code))
+		}
+	})
+
+	t.Run("test response 400 wrong date range plotManagerAlphaVentage", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/plot?symbol=%s&from=2020-05-12&to=2020-01-01", testSymbolReal), nil)
+		response := httptest.NewRecorder()
+		serverAlphaVentage.PlotHandler(request, response)
+		wantCode := 400
+		if response.Code != wantCode {
+			t.Error(fmt.Sprintf("wrong response code, want %d, get %d", wantCode, response.Code))
+		}
+	})
+
+	t.Run("test response 400 adjusted fx plotManagerAlphaVentage", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/plot?symbol=EUR/USD&adjusted=true", nil)
+		response := httptest.NewRecorder()
+		serverAlphaVentage.PlotHandler(request, response)
+		wantCode := 400
+		if response.Code != wantCode {
+			t.Error(fmt.Sprintf("wrong response code, want %d, get %d", wantCode, response.Code))
+		}
+	})
+
+	t.Run("test response 400 wrong asset class plotManagerAlphaVentage", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/plot?symbol=%s&assetClass=bonds", testSymbolReal), nil)
+		response := httptest.NewRecorder()
+		serverAlphaVentage.PlotHandler(request, response)
+		wantCode := 400
+		if response.Code != wantCode {
+			t.Error(fmt.Sprintf("wrong response code, want %d, get %d", wantCode, response.Code))
+		}
+	})
+
+	t.Run("test response 400 adjusted intraday plotManagerAlphaVentage", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/plot?symbol=%s&interval=5min&adjusted=true", testSymbolReal), nil)
+		response := httptest.NewRecorder()
+		serverAlphaVentage.PlotHandler(request, response)
+		wantCode := 400
+		if response.Code != wantCode {
+			t.Error(fmt.Sprintf("wrong response code, want %d, get %d", wantCode, response.Code))
+		}
+	})
+
+	t.Run("test response 404 plotManagerAlphaVentage", func(t *testing.T) {
 		request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/news?symbol=%s", testSymbolUnreal), nil)
 		response := httptest.NewRecorder()
 		serverAlphaVentage.PlotHandler(request, response)
-		wantCode := 500
+		wantCode := 404
 		if response.Code != wantCode {
 			t.Error(fmt.Sprintf("wrong response code, want %d, get %d", wantCode, response.Code))
 		}
@@ -66,7 +128,7 @@ func TestPlotHandler(t *testing.T) {
 }
 
 func TestDBHandler(t *testing.T) {
-	dbManagerMongo := db.NewDBManagerMongo(loadConfig().DBConfig.Name, loadConfig().DBConfig.CollectionTest, loadConfig().DBConfig.Server)
+	dbManagerMongo := db.NewDBManagerMongo(loadConfig().DBConfig.Name, loadConfig().DBConfig.CollectionTest, loadConfig().DBConfig.DBserver)
 	serverDBManagerMongo := NewInvestmentServer(nil, nil, dbManagerMongo)
 
 	t.Run("test response 200 dbManagerMongo", func(t *testing.T) {
@@ -79,3 +141,784 @@ func TestDBHandler(t *testing.T) {
 		}
 	})
 }
+
+// Заглушка PlotManager возвращающая заранее заданные свечи, используется для тестов без обращения к Alpha Vantage
+type stubPlotManager struct {
+	candles []plot.Candle
+	err     error
+}
+
+func (plotManager stubPlotManager) GetPlot(symbol string, options plot.PlotOptions) ([]plot.Candle, error) {
+	if plotManager.err != nil {
+		return nil, plotManager.err
+	}
+	return plot.FilterCandles(plotManager.candles, options.From, options.To), nil
+}
+
+func TestIndicatorsHandler(t *testing.T) {
+	serverStub := NewInvestmentServer(nil, stubPlotManager{candles: plottest.Candles(1, 2, 3, 4, 5)}, nil)
+	tests := []struct {
+		query    string
+		wantCode int
+	}{
+		{"symbol=IBM&name=sma&period=3", 200},
+		{"symbol=IBM&name=obv", 200},
+		{"symbol=IBM&name=sma&period=10", 400},
+		{"symbol=IBM&name=unknown", 400},
+		{"symbol=IBM&name=sma&period=abc", 400},
+		{"symbol=IBM", 400},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %s", test.wantCode, test.query), func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/indicators?"+test.query, nil)
+			response := httptest.NewRecorder()
+			serverStub.IndicatorsHandler(request, response)
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+		})
+	}
+}
+
+func TestResampleHandler(t *testing.T) {
+	serverStub := NewInvestmentServer(nil, stubPlotManager{candles: plottest.Candles(1, 2, 3, 4, 5)}, nil)
+	tests := []struct {
+		query    string
+		wantCode int
+	}{
+		{"symbol=IBM&period=weekly", 200},
+		{"symbol=IBM&period=2d&from=2020-01-02", 200},
+		{"symbol=IBM&period=yearly", 400},
+		{"symbol=IBM", 400},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %s", test.wantCode, test.query), func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/resample?"+test.query, nil)
+			response := httptest.NewRecorder()
+			serverStub.ResampleHandler(request, response)
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+		})
+	}
+}
+
+func TestPlotHandlerProvider(t *testing.T) {
+	plotManagerFallback := plot.NewPlotManagerFallback(
+		plot.Provider{Name: "broken", Manager: stubPlotManager{err: errors.New("exceedApiFrequency")}},
+		plot.Provider{Name: "stub", Manager: stubPlotManager{candles: plottest.Candles(1, 2, 3)}},
+	)
+	serverFallback := NewInvestmentServer(nil, plotManagerFallback, nil)
+
+	t.Run("test response 200 fallback provider", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/plot?symbol=%s", testSymbolReal), nil)
+		response := httptest.NewRecorder()
+		serverFallback.PlotHandler(request, response)
+		wantCode := 200
+		if response.Code != wantCode {
+			t.Error(fmt.Sprintf("wrong response code, want %d, get %d", wantCode, response.Code))
+		}
+		if provider := response.Header().Get("X-Data-Provider"); provider != "stub" {
+			t.Error(fmt.Sprintf("wrong data provider, want stub, get %s", provider))
+		}
+	})
+}
+
+func TestPlotHandlerQuota(t *testing.T) {
+	quotaErr := &alphavantage.QuotaError{RetryAfter: 1500 * time.Millisecond}
+	serverQuota := NewInvestmentServer(nil, stubPlotManager{err: quotaErr}, nil)
+
+	t.Run("test response 429 quota exhausted", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/plot?symbol=%s", testSymbolReal), nil)
+		response := httptest.NewRecorder()
+		serverQuota.PlotHandler(request, response)
+		wantCode := 429
+		if response.Code != wantCode {
+			t.Error(fmt.Sprintf("wrong response code, want %d, get %d", wantCode, response.Code))
+		}
+		if retryAfter := response.Header().Get("Retry-After"); retryAfter != "2" {
+			t.Error(fmt.Sprintf("wrong Retry-After, want 2, get %s", retryAfter))
+		}
+	})
+}
+
+func TestKeysHandler(t *testing.T) {
+	tests := []struct {
+		adminToken    string
+		remoteAddr    string
+		authorization string
+		wantCode      int
+	}{
+		{"", "127.0.0.1:5000", "", 200},
+		{"", "[::1]:5000", "", 200},
+		{"", "192.0.2.1:5000", "", 403},
+		{"secret", "192.0.2.1:5000", "Bearer secret", 200},
+		{"secret", "192.0.2.1:5000", "Bearer wrong", 401},
+		{"secret", "127.0.0.1:5000", "", 401},
+		{"secret", "192.0.2.1:5000", "secret", 401},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %s %q", test.wantCode, test.remoteAddr, test.authorization), func(t *testing.T) {
+			serverKeys := NewInvestmentServer(nil, nil, nil)
+			serverKeys.KeyPool = alphavantage.NewKeyPool("KEY1TEST", "KEY2TEST")
+			serverKeys.AdminToken = test.adminToken
+			request := httptest.NewRequest(http.MethodGet, "/admin/keys", nil)
+			request.RemoteAddr = test.remoteAddr
+			request.Header.Set("Origin", "http://evil.example")
+			if test.authorization != "" {
+				request.Header.Set("Authorization", test.authorization)
+			}
+			response := httptest.NewRecorder()
+			serverKeys.KeysHandler(request, response)
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+			if strings.Contains(response.Body.String(), "KEY1TEST") {
+				t.Error("key is not masked")
+			}
+			if origin := response.Header().Get("Access-Control-Allow-Origin"); origin != "" {
+				t.Error(fmt.Sprintf("CORS header is sent %s", origin))
+			}
+		})
+	}
+}
+
+func TestPlotHandlerErrors(t *testing.T) {
+	tests := []struct {
+		err      error
+		wantCode int
+	}{
+		{fmt.Errorf("%w: Note", alphavantage.ErrRateLimited), 429},
+		{fmt.Errorf("%w: Invalid API call", alphavantage.ErrUnknownSymbol), 404},
+		{alphavantage.ErrInvalidKey, 503},
+		{alphavantage.ErrMalformedResponse, 502},
+		{errors.New("connection refused"), 500},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %v", test.wantCode, test.err), func(t *testing.T) {
+			serverErr := NewInvestmentServer(nil, stubPlotManager{err: test.err}, nil)
+			request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/plot?symbol=%s", testSymbolReal), nil)
+			response := httptest.NewRecorder()
+			serverErr.PlotHandler(request, response)
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+		})
+	}
+}
+
+// Заглушка QuoteManager возвращающая котировки из словаря, для остальных символов возвращает ErrUnknownSymbol
+type stubQuoteManager map[string]float64
+
+func (quoteManager stubQuoteManager) GetQuote(symbol string) (quote.Quote, error) {
+	price, ok := quoteManager[symbol]
+	if !ok {
+		return quote.Quote{}, alphavantage.ErrUnknownSymbol
+	}
+	return quote.Quote{Symbol: symbol, Price: price}, nil
+}
+
+func TestQuoteHandler(t *testing.T) {
+	serverQuote := NewInvestmentServer(nil, nil, nil)
+	serverQuote.QuoteManager = stubQuoteManager{"IBM": 121, "TSLA": 800}
+	serverQuote.QuoteBatchLimit = 3
+	tests := []struct {
+		query    string
+		wantCode int
+		wantBody string
+	}{
+		{"symbol=IBM", 200, `"Price":121`},
+		{"symbol=unrealSymbol", 404, ""},
+		{"symbols=IBM,TSLA,unrealSymbol", 200, `"Symbol":"unrealSymbol","Error":"unknownSymbol"`},
+		{"symbols=,", 400, ""},
+		{"symbols=IBM,TSLA,MSFT,AAPL", 400, ""},
+		{"", 400, ""},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %s", test.wantCode, test.query), func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/quote?"+test.query, nil)
+			response := httptest.NewRecorder()
+			serverQuote.QuoteHandler(request, response)
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+			if !strings.Contains(response.Body.String(), test.wantBody) {
+				t.Error(fmt.Sprintf("wrong response body %s", response.Body.String()))
+			}
+		})
+	}
+}
+
+// Заглушка SymbolSearcher находящая символы из списка по точному совпадению
+type stubSymbolSearcher []string
+
+func (searcher stubSymbolSearcher) Search(query string) ([]search.Match, error) {
+	matches := []search.Match{}
+	for _, symbol := range searcher {
+		if strings.EqualFold(symbol, query) {
+			matches = append(matches, search.Match{Symbol: symbol, Name: symbol, Score: 1})
+		}
+	}
+	return matches, nil
+}
+
+func TestSearchHandler(t *testing.T) {
+	serverSearch := NewInvestmentServer(nil, nil, nil)
+	serverSearch.SymbolSearcher = stubSymbolSearcher{"IBM", "TSLA"}
+	tests := []struct {
+		query    string
+		wantCode int
+	}{
+		{"q=tsla", 200},
+		{"q=", 400},
+		{"", 400},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %s", test.wantCode, test.query), func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/search?"+test.query, nil)
+			response := httptest.NewRecorder()
+			serverSearch.SearchHandler(request, response)
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+		})
+	}
+}
+
+func TestPlotHandlerValidateSymbols(t *testing.T) {
+	serverValidate := NewInvestmentServer(nil, stubPlotManager{candles: plottest.Candles(1, 2, 3)}, nil)
+	serverValidate.SymbolSearcher = stubSymbolSearcher{"IBM"}
+	serverValidate.ValidateSymbols = true
+	tests := []struct {
+		symbol   string
+		wantCode int
+	}{
+		{"IBM", 200},
+		{"ibm", 200},
+		{testSymbolUnreal, 404},
+		{"EUR/USD", 200},
+		{"BTC&assetClass=crypto", 200},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %s", test.wantCode, test.symbol), func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/plot?symbol=%s", test.symbol), nil)
+			response := httptest.NewRecorder()
+			serverValidate.PlotHandler(request, response)
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+		})
+	}
+}
+
+// Заглушка FundamentalsManager знающая только символ IBM
+type stubFundamentalsManager struct{}
+
+func (fundamentalsManager stubFundamentalsManager) GetOverview(symbol string) (fundamentals.Overview, error) {
+	if symbol != "IBM" {
+		return fundamentals.Overview{}, alphavantage.ErrUnknownSymbol
+	}
+	return fundamentals.Overview{Symbol: symbol, Sector: "TECHNOLOGY"}, nil
+}
+
+func (fundamentalsManager stubFundamentalsManager) GetStatements(symbol string, statement fundamentals.Statement) (fundamentals.Statements, error) {
+	if symbol != "IBM" {
+		return fundamentals.Statements{}, alphavantage.ErrUnknownSymbol
+	}
+	return fundamentals.Statements{Symbol: symbol, Statement: statement}, nil
+}
+
+func TestFundamentalsHandler(t *testing.T) {
+	serverFundamentals := NewInvestmentServer(nil, nil, nil)
+	serverFundamentals.FundamentalsManager = stubFundamentalsManager{}
+	tests := []struct {
+		query    string
+		wantCode int
+		wantBody string
+	}{
+		{"symbol=IBM", 200, `"Sector":"TECHNOLOGY"`},
+		{"symbol=IBM&statement=income", 200, `"Statement":"income"`},
+		{"symbol=IBM&statement=profit", 400, ""},
+		{"symbol=unrealSymbol&statement=balance", 404, ""},
+		{"statement=income", 400, ""},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %s", test.wantCode, test.query), func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/fundamentals?"+test.query, nil)
+			response := httptest.NewRecorder()
+			serverFundamentals.FundamentalsHandler(request, response)
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+			if !strings.Contains(response.Body.String(), test.wantBody) {
+				t.Error(fmt.Sprintf("wrong response body %s", response.Body.String()))
+			}
+		})
+	}
+}
+
+// Заглушка CalendarManager возвращающая по одному событию каждого вида для символа IBM
+type stubCalendarManager struct{}
+
+func (calendarManager stubCalendarManager) GetEvents(symbol string, kind calendar.Kind) ([]calendar.Event, error) {
+	if symbol != "IBM" {
+		return nil, alphavantage.ErrUnknownSymbol
+	}
+	events := []calendar.Event{}
+	if kind == "" || kind == calendar.KindEarnings {
+		date := time.Date(2020, 1, 21, 0, 0, 0, 0, time.UTC)
+		events = append(events, calendar.Event{Symbol: symbol, Kind: calendar.KindEarnings, Date: date, CandleDate: date.AddDate(0, 0, 1)})
+	}
+	if kind == "" || kind == calendar.KindDividends {
+		date := time.Date(2020, 2, 7, 0, 0, 0, 0, time.UTC)
+		events = append(events, calendar.Event{Symbol: symbol, Kind: calendar.KindDividends, Date: date, CandleDate: date})
+	}
+	return events, nil
+}
+
+func TestCalendarHandler(t *testing.T) {
+	serverCalendar := NewInvestmentServer(nil, nil, nil)
+	serverCalendar.CalendarManager = stubCalendarManager{}
+	tests := []struct {
+		query    string
+		wantCode int
+		wantBody string
+	}{
+		{"symbol=IBM&kind=earnings", 200, `"CandleDate":"2020-01-22T00:00:00Z"`},
+		{"symbol=IBM&from=2020-02-01", 200, `[{"Symbol":"IBM","Kind":"dividends"`},
+		{"symbol=IBM&kind=splits", 400, ""},
+		{"symbol=unrealSymbol", 404, ""},
+		{"kind=earnings", 400, ""},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %s", test.wantCode, test.query), func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/calendar?"+test.query, nil)
+			response := httptest.NewRecorder()
+			serverCalendar.CalendarHandler(request, response)
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+			if !strings.Contains(response.Body.String(), test.wantBody) {
+				t.Error(fmt.Sprintf("wrong response body %s", response.Body.String()))
+			}
+		})
+	}
+}
+
+// Заглушка RateSource с постоянным курсом для любой пары валют
+type stubRateSource float64
+
+func (rateSource stubRateSource) GetRates(from, to string, start, end time.Time) ([]fx.Rate, error) {
+	return []fx.Rate{{Date: start, Rate: float64(rateSource)}}, nil
+}
+
+// Заглушка SymbolSearcher сообщающая валюту торгов и считающая вызовы поиска
+type currencySymbolSearcher struct {
+	currencies map[string]string
+	calls      *int
+}
+
+func (searcher currencySymbolSearcher) Search(query string) ([]search.Match, error) {
+	*searcher.calls++
+	matches := []search.Match{}
+	if currency, ok := searcher.currencies[strings.ToUpper(query)]; ok {
+		matches = append(matches, search.Match{Symbol: strings.ToUpper(query), Currency: currency, Score: 1})
+	}
+	return matches, nil
+}
+
+func TestPlotHandlerCurrency(t *testing.T) {
+	calls := 0
+	serverCurrency := NewInvestmentServer(nil, stubPlotManager{candles: plottest.Candles(10, 20)}, nil)
+	serverCurrency.RateSource = stubRateSource(0.5)
+	serverCurrency.Currencies = search.NewCurrencyCache(currencySymbolSearcher{map[string]string{"SAP.DEX": "EUR"}, &calls})
+	tests := []struct {
+		query     string
+		wantCode  int
+		wantBody  string
+		wantCalls int
+	}{
+		{"symbol=IBM&currency=eur", 200, `"Close":5,`, 1},
+		{"symbol=IBM&currency=eur", 200, `"Close":5,`, 1},
+		{"symbol=SAP.DEX&currency=EUR", 200, `"Close":10,`, 2},
+		{"symbol=SAP.DEX&currency=EUR", 200, `"Close":10,`, 2},
+		{"symbol=EUR/USD&currency=USD", 200, `"Close":10,`, 2},
+		{"symbol=IBM&currency=EURO!", 400, "", 2},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %s", test.wantCode, test.query), func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/plot?"+test.query, nil)
+			response := httptest.NewRecorder()
+			serverCurrency.PlotHandler(request, response)
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+			if !strings.Contains(response.Body.String(), test.wantBody) {
+				t.Error(fmt.Sprintf("wrong response body %s", response.Body.String()))
+			}
+			if calls != test.wantCalls {
+				t.Error(fmt.Sprintf("wrong number of searches, want %d, get %d", test.wantCalls, calls))
+			}
+		})
+	}
+}
+
+func TestCompareHandler(t *testing.T) {
+	serverCompare := NewInvestmentServer(nil, stubPlotManager{candles: plottest.Candles(10, 20, 25)}, nil)
+	tests := []struct {
+		query    string
+		wantCode int
+		wantBody string
+	}{
+		{"symbols=IBM,MSFT", 200, `"TotalReturn":1.5`},
+		{"symbols=IBM&from=2020-01-02", 200, `"Close":100,`},
+		{"symbols=,", 400, ""},
+		{"symbols=A,B,C,D,E,F,G,H,I,J,K", 400, ""},
+		{"symbols=IBM&interval=2min", 400, ""},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %s", test.wantCode, test.query), func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/compare?"+test.query, nil)
+			response := httptest.NewRecorder()
+			serverCompare.CompareHandler(request, response)
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+			if !strings.Contains(response.Body.String(), test.wantBody) {
+				t.Error(fmt.Sprintf("wrong response body %s", response.Body.String()))
+			}
+		})
+	}
+
+	t.Run("test response 404 unknown symbol", func(t *testing.T) {
+		serverErr := NewInvestmentServer(nil, stubPlotManager{err: alphavantage.ErrUnknownSymbol}, nil)
+		request := httptest.NewRequest(http.MethodGet, "/compare?symbols=IBM,unrealSymbol", nil)
+		response := httptest.NewRecorder()
+		serverErr.CompareHandler(request, response)
+		wantCode := 404
+		if response.Code != wantCode {
+			t.Error(fmt.Sprintf("wrong response code, want %d, get %d", wantCode, response.Code))
+		}
+	})
+}
+
+func TestStatsHandler(t *testing.T) {
+	serverStats := NewInvestmentServer(nil, stubPlotManager{candles: plottest.Candles(100, 110, 99, 108.9)}, nil)
+	tests := []struct {
+		query    string
+		wantCode int
+		wantBody string
+	}{
+		{"symbol=IBM&benchmark=SPY", 200, `"Beta":1`},
+		{"symbol=IBM&riskFree=0.02", 200, `"Peak":"2020-01-02T00:00:00Z","Trough":"2020-01-03T00:00:00Z"`},
+		{"symbol=IBM&riskFree=abc", 400, ""},
+		{"symbol=IBM&from=2020-01-03", 400, ""},
+		{"benchmark=SPY", 400, ""},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %s", test.wantCode, test.query), func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/stats?"+test.query, nil)
+			response := httptest.NewRecorder()
+			serverStats.StatsHandler(request, response)
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+			if !strings.Contains(response.Body.String(), test.wantBody) {
+				t.Error(fmt.Sprintf("wrong response body %s", response.Body.String()))
+			}
+		})
+	}
+}
+
+func TestPlotImageHandler(t *testing.T) {
+	serverImage := NewInvestmentServer(nil, stubPlotManager{candles: plottest.Candles(1, 2, 3, 4, 5, 4, 3)}, nil)
+	tests := []struct {
+		path     string
+		query    string
+		wantCode int
+		wantType string
+		wantBody string
+	}{
+		{"/plot.svg", "symbol=IBM", 200, "image/svg+xml", `width="800" height="400"`},
+		{"/plot.svg", "symbol=IBM&width=300&height=200&theme=dark&volume=false", 200, "image/svg+xml", `width="300" height="200"`},
+		{"/plot.svg", "symbol=IBM&overlays=sma:3,ema:2", 200, "image/svg+xml", "<polyline"},
+		{"/plot.svg", "symbol=IBM&overlays=bollinger:3", 200, "image/svg+xml", "bollinger upper"},
+		{"/plot.png", "symbol=IBM&width=400&height=300", 200, "image/png", "\x89PNG"},
+		{"/plot.svg", "symbol=IBM&overlays=rsi", 400, "", ""},
+		{"/plot.svg", "symbol=IBM&overlays=sma:10", 400, "", ""},
+		{"/plot.svg", "symbol=IBM&overlays=sma:x", 400, "", ""},
+		{"/plot.svg", "symbol=IBM&overlays=sma,sma,sma,sma,sma,sma", 400, "", ""},
+		{"/plot.svg", "symbol=IBM&theme=blue", 400, "", ""},
+		{"/plot.png", "symbol=IBM&width=10", 400, "", ""},
+		{"/plot.png", "symbol=IBM&height=100000", 400, "", ""},
+		{"/plot.png", "symbol=IBM&volume=maybe", 400, "", ""},
+		{"/plot.png", "", 400, "", ""},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %s?%s", test.wantCode, test.path, test.query), func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, test.path+"?"+test.query, nil)
+			response := httptest.NewRecorder()
+			if test.path == "/plot.png" {
+				serverImage.PlotPNGHandler(request, response)
+			} else {
+				serverImage.PlotSVGHandler(request, response)
+			}
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+			if test.wantType != "" && response.Header().Get("Content-Type") != test.wantType {
+				t.Error(fmt.Sprintf("wrong content type %s", response.Header().Get("Content-Type")))
+			}
+			if !strings.Contains(response.Body.String(), test.wantBody) {
+				t.Error(fmt.Sprintf("wrong response body %.200s", response.Body.String()))
+			}
+		})
+	}
+}
+
+func TestBacktestHandler(t *testing.T) {
+	serverBacktest := NewInvestmentServer(nil, stubPlotManager{candles: plottest.Candles(10, 11, 12, 13, 12, 11, 10, 11, 12, 13)}, nil)
+	tests := []struct {
+		method   string
+		query    string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{http.MethodPost, "symbol=IBM", `{"Strategy": {"Type": "buyAndHold"}, "InitialCapital": 1000}`, 200, `"Symbol":"IBM","Strategy":"buyAndHold"`},
+		{http.MethodPost, "symbol=IBM", `{"strategy": {"type": "smaCross", "fast": 2, "slow": 3}, "commission": 0.001}`, 200, `"Strategy":"smaCross(2,3)"`},
+		{http.MethodPost, "symbol=IBM", `{"Strategy": {"Type": "rsi", "Period": 3}}`, 200, `"Strategy":"rsi(3,30,70)"`},
+		{http.MethodPost, "symbol=IBM", `{"Strategy": {"Type": "smaCross", "Fast": 5, "Slow": 20}}`, 400, ""},
+		{http.MethodPost, "symbol=IBM", `{"Strategy": {"Type": "unknown"}}`, 400, ""},
+		{http.MethodPost, "symbol=IBM", `{"Strategy": {"Type": "buyAndHold"}, "Slippage": 2}`, 400, ""},
+		{http.MethodPost, "symbol=IBM", `{"Strategy": {"Type": "buyAndHold"}, "Symbol": "IBM"}`, 400, ""},
+		{http.MethodPost, "symbol=IBM", `not json`, 400, ""},
+		{http.MethodPost, "", `{"Strategy": {"Type": "buyAndHold"}}`, 400, ""},
+		{http.MethodGet, "symbol=IBM", "", 405, ""},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %s %s", test.wantCode, test.query, test.body), func(t *testing.T) {
+			request := httptest.NewRequest(test.method, "/backtest?"+test.query, strings.NewReader(test.body))
+			response := httptest.NewRecorder()
+			serverBacktest.BacktestHandler(request, response)
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+			if !strings.Contains(response.Body.String(), test.wantBody) {
+				t.Error(fmt.Sprintf("wrong response body %s", response.Body.String()))
+			}
+		})
+	}
+}
+
+// Менеджер портфелей хранящий операции в памяти
+type stubPortfolioManager struct {
+	transactions *[]db.Transaction
+	err          error
+}
+
+func (portfolioManager stubPortfolioManager) GetTransactions(userID string) ([]db.Transaction, error) {
+	if portfolioManager.err != nil {
+		return nil, portfolioManager.err
+	}
+	transactions := []db.Transaction{}
+	for _, transaction := range *portfolioManager.transactions {
+		if transaction.UserID == userID {
+			transactions = append(transactions, transaction)
+		}
+	}
+	return transactions, nil
+}
+
+func (portfolioManager stubPortfolioManager) AddTransaction(transaction db.Transaction) error {
+	if portfolioManager.err != nil {
+		return portfolioManager.err
+	}
+	*portfolioManager.transactions = append(*portfolioManager.transactions, transaction)
+	return nil
+}
+
+func TestPortfolioTransactionsHandler(t *testing.T) {
+	serverPortfolio := NewInvestmentServer(nil, stubPlotManager{}, nil)
+	serverPortfolio.PortfolioManager = stubPortfolioManager{transactions: &[]db.Transaction{}}
+	serverBroken := NewInvestmentServer(nil, stubPlotManager{}, nil)
+	serverBroken.PortfolioManager = stubPortfolioManager{err: errors.New("connection refused")}
+	serverNoPortfolio := NewInvestmentServer(nil, stubPlotManager{}, nil)
+	tests := []struct {
+		server   InvestmentServer
+		method   string
+		query    string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{serverPortfolio, http.MethodPost, "user=u1", `{"Symbol": "ibm", "Type": "buy", "Date": "2020-01-02", "Quantity": 10, "Price": 100, "Fees": 1}`, 200, `"Symbol":"IBM","Type":"buy","Date":"2020-01-02T00:00:00Z"`},
+		{serverPortfolio, http.MethodPost, "user=u1", `{"symbol": "IBM", "type": "SELL", "date": "2020-01-03", "quantity": 4, "price": 110}`, 200, `"Type":"sell"`},
+		{serverPortfolio, http.MethodPost, "user=u1", `{"Symbol": "IBM", "Type": "sell", "Date": "2020-01-04", "Quantity": 7, "Price": 110}`, 400, ""},
+		{serverPortfolio, http.MethodPost, "user=u1", `{"Symbol": "IBM", "Type": "sell", "Date": "2020-01-01", "Quantity": 1, "Price": 110}`, 400, ""},
+		{serverPortfolio, http.MethodPost, "user=u1", `{"Symbol": "IBM", "Type": "short", "Quantity": 1, "Price": 110}`, 400, ""},
+		{serverPortfolio, http.MethodPost, "user=u1", `{"Symbol": "IBM", "Type": "buy", "Date": "02.01.2020", "Quantity": 1, "Price": 110}`, 400, ""},
+		{serverPortfolio, http.MethodPost, "user=u1", `{"Symbol": "IBM", "Type": "buy", "Quantity": 1, "Price": 110, "Account": "x"}`, 400, ""},
+		{serverPortfolio, http.MethodPost, "user=u2", `{"Symbol": "AAPL", "Type": "buy", "Quantity": 1, "Price": 300}`, 200, `"UserID":"u2"`},
+		{serverPortfolio, http.MethodGet, "user=u1", "", 200, `"Quantity":10,"Price":100,"Fees":1},{"UserID":"u1","Symbol":"IBM","Type":"sell"`},
+		{serverPortfolio, http.MethodGet, "", "", 400, ""},
+		{serverPortfolio, http.MethodDelete, "user=u1", "", 405, ""},
+		{serverBroken, http.MethodGet, "user=u1", "", 500, ""},
+		{serverNoPortfolio, http.MethodGet, "user=u1", "", 404, ""},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %s %s %s", test.wantCode, test.method, test.query, test.body), func(t *testing.T) {
+			request := httptest.NewRequest(test.method, "/portfolio/transactions?"+test.query, strings.NewReader(test.body))
+			response := httptest.NewRecorder()
+			test.server.PortfolioTransactionsHandler(request, response)
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+			if !strings.Contains(response.Body.String(), test.wantBody) {
+				t.Error(fmt.Sprintf("wrong response body %s", response.Body.String()))
+			}
+		})
+	}
+}
+
+func TestPortfolioPositionsHandler(t *testing.T) {
+	transactions := []db.Transaction{
+		{UserID: "u1", Symbol: "IBM", Type: db.TransactionBuy, Date: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), Quantity: 10, Price: 10},
+		{UserID: "u1", Symbol: "IBM", Type: db.TransactionBuy, Date: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), Quantity: 10, Price: 20},
+		{UserID: "u1", Symbol: "IBM", Type: db.TransactionSell, Date: time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC), Quantity: 10, Price: 30},
+	}
+	now := time.Now().UTC().Truncate(24 * time.Hour)
+	candles := []plot.Candle{{Date: now.AddDate(0, 0, -1), Close: 24}, {Date: now, Close: 25}}
+	serverPortfolio := NewInvestmentServer(nil, stubPlotManager{candles: candles}, nil)
+	serverPortfolio.PortfolioManager = stubPortfolioManager{transactions: &transactions}
+	serverNoPlot := NewInvestmentServer(nil, stubPlotManager{err: alphavantage.ErrUnknownSymbol}, nil)
+	serverNoPlot.PortfolioManager = stubPortfolioManager{transactions: &transactions}
+	tests := []struct {
+		server   InvestmentServer
+		query    string
+		wantCode int
+		wantBody string
+	}{
+		{serverPortfolio, "user=u1", 200, `"Method":"fifo","Positions":[{"Symbol":"IBM","Quantity":10,"CostBasis":200,"AverageCost":20,"RealizedPnL":200,"Fees":0,"Price":25`},
+		{serverPortfolio, "user=u1&method=average", 200, `"CostBasis":150,"AverageCost":15,"RealizedPnL":150`},
+		{serverPortfolio, "user=u1", 200, `"MarketValue":250,"RealizedPnL":200,"UnrealizedPnL":50}`},
+		{serverPortfolio, "user=u2", 200, `"Positions":[]`},
+		{serverPortfolio, "user=u1&method=lifo", 400, ""},
+		{serverPortfolio, "", 400, ""},
+		{serverNoPlot, "user=u1", 404, ""},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %s", test.wantCode, test.query), func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/portfolio/positions?"+test.query, nil)
+			response := httptest.NewRecorder()
+			test.server.PortfolioPositionsHandler(request, response)
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+			if !strings.Contains(response.Body.String(), test.wantBody) {
+				t.Error(fmt.Sprintf("wrong response body %s", response.Body.String()))
+			}
+		})
+	}
+}
+
+// Менеджер графиков считающий обращения к нему
+type countingPlotManager struct {
+	candles []plot.Candle
+	calls   *int32
+}
+
+func (plotManager countingPlotManager) GetPlot(symbol string, options plot.PlotOptions) ([]plot.Candle, error) {
+	atomic.AddInt32(plotManager.calls, 1)
+	return plot.FilterCandles(plotManager.candles, options.From, options.To), nil
+}
+
+func TestPortfolioPositionsHandlerCache(t *testing.T) {
+	transactions := []db.Transaction{
+		{UserID: "u1", Symbol: "IBM", Type: db.TransactionBuy, Date: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), Quantity: 10, Price: 10},
+	}
+	var calls int32
+	now := time.Now().UTC().Truncate(24 * time.Hour)
+	plotManager := countingPlotManager{candles: []plot.Candle{{Date: now, Close: 25}}, calls: &calls}
+	cacheManager := cache.NewCacheManager(plotManager, nil, cache.NewBackendLRU(10), cache.TTL{})
+	serverCached := NewInvestmentServer(nil, cacheManager, nil)
+	serverCached.PortfolioManager = stubPortfolioManager{transactions: &transactions}
+	for i := 0; i < 2; i++ {
+		request := httptest.NewRequest(http.MethodGet, "/portfolio/positions?user=u1", nil)
+		response := httptest.NewRecorder()
+		serverCached.PortfolioPositionsHandler(request, response)
+		if response.Code != http.StatusOK {
+			t.Fatal(fmt.Sprintf("wrong response code, want %d, get %d", http.StatusOK, response.Code))
+		}
+	}
+	if calls != 1 {
+		t.Error(fmt.Sprintf("plot requested upstream %d times", calls))
+	}
+}
+
+func TestPortfolioPerformanceHandler(t *testing.T) {
+	transactions := []db.Transaction{
+		{UserID: "u1", Symbol: "IBM", Type: db.TransactionBuy, Date: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), Quantity: 10, Price: 1},
+	}
+	serverPortfolio := NewInvestmentServer(nil, stubPlotManager{candles: plottest.Candles(1, 2, 3, 4)}, nil)
+	serverPortfolio.PortfolioManager = stubPortfolioManager{transactions: &transactions}
+	serverNoPlot := NewInvestmentServer(nil, stubPlotManager{err: alphavantage.ErrUnknownSymbol}, nil)
+	serverNoPlot.PortfolioManager = stubPortfolioManager{transactions: &transactions}
+	serverNoPortfolio := NewInvestmentServer(nil, stubPlotManager{}, nil)
+	tests := []struct {
+		server   InvestmentServer
+		query    string
+		wantCode int
+		wantBody string
+	}{
+		{serverPortfolio, "user=u1", 200, `"Portfolio":{"Symbol":"portfolio","Candles":[`},
+		{serverPortfolio, "user=u1", 200, `"Flows":[{"Date":"2020-01-01T00:00:00Z","Amount":-10}],"TimeWeightedReturn":3`},
+		{serverPortfolio, "user=u1&benchmark=SPY", 200, `"Benchmark":{"Symbol":"SPY","Candles":[`},
+		{serverPortfolio, "user=u1&benchmark=SPY", 200, `"ExcessReturn":0`},
+		{serverPortfolio, "user=u2", 404, ""},
+		{serverPortfolio, "", 400, ""},
+		{serverNoPlot, "user=u1", 404, ""},
+		{serverNoPortfolio, "user=u1", 404, ""},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %s", test.wantCode, test.query), func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/portfolio/performance?"+test.query, nil)
+			response := httptest.NewRecorder()
+			test.server.PortfolioPerformanceHandler(request, response)
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+			if !strings.Contains(response.Body.String(), test.wantBody) {
+				t.Error(fmt.Sprintf("wrong response body %s", response.Body.String()))
+			}
+		})
+	}
+}
+
+func TestPatternsHandler(t *testing.T) {
+	candles := plottest.Candles(10, 10)
+	candles[1].High, candles[1].Low = 11, 9
+	serverPatterns := NewInvestmentServer(nil, stubPlotManager{candles: candles}, nil)
+	tests := []struct {
+		query    string
+		wantCode int
+		wantBody string
+	}{
+		{"symbol=IBM", 200, `"Pattern":"doji","Date":"2020-01-02T00:00:00Z","Index":1`},
+		{"symbol=IBM&interval=2min", 400, ""},
+		{"", 400, ""},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test response %d %s", test.wantCode, test.query), func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/patterns?"+test.query, nil)
+			response := httptest.NewRecorder()
+			serverPatterns.PatternsHandler(request, response)
+			if response.Code != test.wantCode {
+				t.Error(fmt.Sprintf("wrong response code, want %d, get %d", test.wantCode, response.Code))
+			}
+			if !strings.Contains(response.Body.String(), test.wantBody) {
+				t.Error(fmt.Sprintf("wrong response body %s", response.Body.String()))
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package alphavantage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Ошибки Alpha Vantage, проверяются через errors.Is
+var (
+	ErrRateLimited       = errors.New("rateLimited")       // превышена частота запросов или суточный лимит ключа
+	ErrUnknownSymbol     = errors.New("unknownSymbol")     // символ финансового актива не найден
+	ErrInvalidKey        = errors.New("invalidKey")        // ключ API неверный или не передан
+	ErrMalformedResponse = errors.New("malformedResponse") // ответ не удалось разобрать
+)
+
+// Структура errorResponse содержит поля которыми Alpha Vantage сообщает об ошибках вместо данных
+type errorResponse struct {
+	Note         string `json:"Note"`          // сообщение о превышении частоты запросов
+	Information  string `json:"Information"`   // сообщение о лимите, неверном ключе или премиум функции
+	ErrorMessage string `json:"Error Message"` // сообщение о неверном запросе
+}
+
+// Метод проверяющий JSON ответ Alpha Vantage на сообщения об ошибках,
+// возвращает nil если ошибок нет или ошибку оборачивающую одну из ErrRateLimited, ErrUnknownSymbol, ErrInvalidKey, ErrMalformedResponse
+func CheckResponse(body []byte) error {
+	var response errorResponse
+	err := json.Unmarshal(body, &response)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedResponse, err)
+	}
+	switch {
+	case response.ErrorMessage != "":
+		if mentionsKey(response.ErrorMessage) {
+			return fmt.Errorf("%w: %s", ErrInvalidKey, response.ErrorMessage)
+		}
+		return fmt.Errorf("%w: %s", ErrUnknownSymbol, response.ErrorMessage)
+	case response.Note != "":
+		return fmt.Errorf("%w: %s", ErrRateLimited, response.Note)
+	case response.Information != "":
+		information := strings.ToLower(response.Information)
+		switch {
+		case strings.Contains(information, "rate limit") || strings.Contains(information, "call frequency"):
+			return fmt.Errorf("%w: %s", ErrRateLimited, response.Information)
+		case mentionsKey(response.Information):
+			return fmt.Errorf("%w: %s", ErrInvalidKey, response.Information)
+		}
+		return errors.New(response.Information)
+	}
+	return nil
+}
+
+// Вспомогательный метод возвращающий true если сообщение говорит о неверном или отсутствующем ключе
+func mentionsKey(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "apikey") && (strings.Contains(message, "invalid") || strings.Contains(message, "missing"))
+}
+
+// Метод позволяющий errors.Is считать QuotaError ошибкой ErrRateLimited
+func (quotaErr *QuotaError) Is(target error) bool {
+	return target == ErrRateLimited
+}
@@ -0,0 +1,51 @@
+package alphavantage
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want error
+	}{
+		{"data", `{"Meta Data": {"1. Information": "Daily Prices"}, "Time Series (Daily)": {}}`, nil},
+		{"note", `{"Note": "Thank you for using Alpha Vantage! Our standard API call frequency is 5 calls per minute"}`, ErrRateLimited},
+		{"daily limit", `{"Information": "Our standard API rate limit is 25 requests per day."}`, ErrRateLimited},
+		{"invalid call", `{"Error Message": "Invalid API call. Please retry or visit the documentation for TIME_SERIES_DAILY."}`, ErrUnknownSymbol},
+		{"invalid key", `{"Error Message": "the parameter apikey is invalid or missing."}`, ErrInvalidKey},
+		{"missing key info", `{"Information": "Please specify a valid apikey. The parameter apikey is missing."}`, ErrInvalidKey},
+		{"malformed", `<html>Note</html>`, ErrMalformedResponse},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckResponse([]byte(test.body))
+			if test.want == nil {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, test.want) {
+				t.Errorf("wrong error, want %v, get %v", test.want, err)
+			}
+		})
+	}
+
+	t.Run("premium", func(t *testing.T) {
+		err := CheckResponse([]byte(`{"Information": "Thank you for using Alpha Vantage! This is a premium endpoint."}`))
+		if err == nil || errors.Is(err, ErrRateLimited) || errors.Is(err, ErrInvalidKey) {
+			t.Errorf("wrong error %v", err)
+		}
+	})
+}
+
+func TestQuotaErrorIsRateLimited(t *testing.T) {
+	var err error = &QuotaError{RetryAfter: time.Second}
+	if !errors.Is(err, ErrRateLimited) {
+		t.Error("QuotaError is not ErrRateLimited")
+	}
+}
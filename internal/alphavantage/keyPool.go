@@ -0,0 +1,140 @@
+package alphavantage
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Структура KeyPool хранит несколько ключей Alpha Vantage и выдает их по кругу,
+// пропуская ключи отправленные на карантин после ответа о превышении лимита
+type KeyPool struct {
+	mutex sync.Mutex
+	keys  []*keyState
+	next  int
+	now   func() time.Time // текущее время, подменяется в тестах
+}
+
+// Состояние одного ключа
+type keyState struct {
+	key              string
+	requests         int       // сколько раз ключ был выдан
+	throttled        int       // сколько раз Alpha Vantage ответил о превышении лимита
+	consecutive      int       // сколько раз подряд ключ получил отказ без успешных запросов между ними
+	quarantinedUntil time.Time // до какого момента ключ не выдается
+}
+
+// Структура KeyUsage содержит статистику использования ключа для административного просмотра
+type KeyUsage struct {
+	Key              string    // ключ, видны только первые символы
+	Requests         int       // сколько раз ключ был выдан
+	Throttled        int       // сколько раз Alpha Vantage ответил о превышении лимита
+	Quarantined      bool      // находится ли ключ на карантине
+	QuarantinedUntil time.Time // до какого момента ключ на карантине
+}
+
+// Конструктор для структуры KeyPool, пустые ключи пропускаются
+func NewKeyPool(keys ...string) *KeyPool {
+	pool := &KeyPool{now: time.Now}
+	for _, key := range keys {
+		if key != "" {
+			pool.keys = append(pool.keys, &keyState{key: key})
+		}
+	}
+	return pool
+}
+
+// Метод возвращающий количество ключей в пуле
+func (pool *KeyPool) Len() int {
+	return len(pool.keys)
+}
+
+// Метод выдающий следующий по кругу ключ не находящийся на карантине,
+// если все ключи на карантине возвращает QuotaError с временем до окончания ближайшего карантина
+func (pool *KeyPool) Next() (string, error) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	if len(pool.keys) == 0 {
+		return "", errors.New("noApiKeys")
+	}
+	now := pool.now()
+	var retryAfter time.Duration
+	for i := 0; i < len(pool.keys); i++ {
+		state := pool.keys[(pool.next+i)%len(pool.keys)]
+		if wait := state.quarantinedUntil.Sub(now); wait > 0 {
+			if retryAfter == 0 || wait < retryAfter {
+				retryAfter = wait
+			}
+			continue
+		}
+		pool.next = (pool.next + i + 1) % len(pool.keys)
+		state.requests++
+		return state.key, nil
+	}
+	return "", &QuotaError{RetryAfter: retryAfter}
+}
+
+// Метод отправляющий ключ на карантин после ответа Alpha Vantage о превышении лимита:
+// при первом отказе до начала следующей минуты, при повторном отказе подряд - до конца суток (UTC)
+func (pool *KeyPool) MarkThrottled(key string) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	state := pool.find(key)
+	if state == nil {
+		return
+	}
+	now := pool.now().UTC()
+	state.throttled++
+	state.consecutive++
+	if state.consecutive > 1 {
+		state.quarantinedUntil = time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+		return
+	}
+	state.quarantinedUntil = now.Truncate(time.Minute).Add(time.Minute)
+}
+
+// Метод отмечающий успешный запрос с ключом, сбрасывает счетчик отказов подряд
+func (pool *KeyPool) MarkSuccess(key string) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	if state := pool.find(key); state != nil {
+		state.consecutive = 0
+	}
+}
+
+// Метод возвращающий статистику использования всех ключей
+func (pool *KeyPool) Usage() []KeyUsage {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	now := pool.now()
+	usage := make([]KeyUsage, len(pool.keys))
+	for i, state := range pool.keys {
+		usage[i] = KeyUsage{
+			Key:              maskKey(state.key),
+			Requests:         state.requests,
+			Throttled:        state.throttled,
+			Quarantined:      state.quarantinedUntil.After(now),
+			QuarantinedUntil: state.quarantinedUntil,
+		}
+	}
+	return usage
+}
+
+// Вспомогательный метод ищущий состояние ключа
+func (pool *KeyPool) find(key string) *keyState {
+	for _, state := range pool.keys {
+		if state.key == key {
+			return state
+		}
+	}
+	return nil
+}
+
+// Вспомогательный метод скрывающий ключ, оставляет видимыми первые 4 символа
+func maskKey(key string) string {
+	if len(key) <= 4 {
+		return strings.Repeat("*", len(key))
+	}
+	return key[:4] + strings.Repeat("*", len(key)-4)
+}
@@ -0,0 +1,74 @@
+package alphavantage
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKeyPoolRotation(t *testing.T) {
+	pool := NewKeyPool("KEY1", "", "KEY2", "KEY3")
+	if pool.Len() != 3 {
+		t.Fatalf("wrong pool length, want 3, get %d", pool.Len())
+	}
+	want := []string{"KEY1", "KEY2", "KEY3", "KEY1"}
+	for i, wantKey := range want {
+		key, err := pool.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key != wantKey {
+			t.Errorf("wrong key %d, want %s, get %s", i, wantKey, key)
+		}
+	}
+}
+
+func TestKeyPoolQuarantine(t *testing.T) {
+	pool := NewKeyPool("KEY1", "KEY2")
+	now := time.Date(2020, 5, 12, 10, 0, 20, 0, time.UTC)
+	pool.now = func() time.Time { return now }
+
+	pool.MarkThrottled("KEY1")
+	for i := 0; i < 2; i++ {
+		if key, _ := pool.Next(); key != "KEY2" {
+			t.Errorf("wrong key, want KEY2, get %s", key)
+		}
+	}
+	pool.MarkThrottled("KEY2")
+	_, err := pool.Next()
+	var quotaErr *QuotaError
+	if !errors.As(err, &quotaErr) || quotaErr.RetryAfter != 40*time.Second {
+		t.Fatalf("wrong error %v", err)
+	}
+
+	// после окончания минуты ключ возвращается, повторный отказ подряд отправляет его на карантин до конца суток
+	now = now.Add(time.Minute)
+	if key, err := pool.Next(); err != nil || key != "KEY1" {
+		t.Fatalf("wrong key %s, error %v", key, err)
+	}
+	pool.MarkThrottled("KEY1")
+	usage := pool.Usage()
+	if !usage[0].Quarantined || !usage[0].QuarantinedUntil.Equal(time.Date(2020, 5, 13, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("wrong quarantine %+v", usage[0])
+	}
+	if usage[0].Key != "****" || usage[0].Throttled != 2 {
+		t.Errorf("wrong usage %+v", usage[0])
+	}
+
+	// успешный запрос сбрасывает счетчик отказов подряд
+	now = now.Add(time.Minute)
+	pool.MarkSuccess("KEY2")
+	pool.MarkThrottled("KEY2")
+	if until := pool.Usage()[1].QuarantinedUntil; !until.Equal(time.Date(2020, 5, 12, 10, 3, 0, 0, time.UTC)) {
+		t.Errorf("wrong quarantine end %s", until)
+	}
+}
+
+func TestMaskKey(t *testing.T) {
+	if masked := maskKey("RFQVPDIH6W9SQV2O"); masked != "RFQV************" {
+		t.Errorf("wrong masked key %s", masked)
+	}
+	if masked := maskKey("KEY"); masked != "***" {
+		t.Errorf("wrong masked key %s", masked)
+	}
+}
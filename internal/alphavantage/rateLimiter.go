@@ -0,0 +1,97 @@
+package alphavantage
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Структура RateLimiter ограничивает частоту запросов к Alpha Vantage двумя корзинами токенов: поминутной и суточной.
+// Если токенов нет, запрос встает в очередь и ждет, но не дольше MaxWait, иначе возвращается QuotaError
+type RateLimiter struct {
+	MaxWait time.Duration // максимальное время ожидания в очереди
+
+	mutex  sync.Mutex
+	minute tokenBucket
+	day    tokenBucket
+	now    func() time.Time    // текущее время, подменяется в тестах
+	sleep  func(time.Duration) // ожидание, подменяется в тестах
+}
+
+// Корзина токенов, пополняется равномерно до capacity за period, может уходить в минус на число ожидающих в очереди запросов
+type tokenBucket struct {
+	capacity float64
+	period   time.Duration
+	tokens   float64
+	updated  time.Time
+}
+
+// Ограничитель по умолчанию для бесплатного ключа Alpha Vantage (5 запросов в минуту, 500 в сутки),
+// общий для всех менеджеров созданных без явного ограничителя
+var DefaultRateLimiter = NewRateLimiter(5, 500, 30*time.Second)
+
+// Конструктор для структуры RateLimiter, принимает количество запросов в минуту, в сутки и максимальное время ожидания
+func NewRateLimiter(perMinute, perDay int, maxWait time.Duration) *RateLimiter {
+	now := time.Now()
+	return &RateLimiter{
+		MaxWait: maxWait,
+		minute:  tokenBucket{float64(perMinute), time.Minute, float64(perMinute), now},
+		day:     tokenBucket{float64(perDay), 24 * time.Hour, float64(perDay), now},
+		now:     time.Now,
+		sleep:   time.Sleep,
+	}
+}
+
+// Метод занимающий место для одного запроса, если свободных токенов нет - ждет своей очереди.
+// Если ждать придется дольше MaxWait, ничего не занимает и возвращает QuotaError с подсказкой когда повторить запрос
+func (limiter *RateLimiter) Wait() error {
+	limiter.mutex.Lock()
+	now := limiter.now()
+	limiter.minute.refill(now)
+	limiter.day.refill(now)
+	wait := limiter.minute.waitFor(1)
+	if dayWait := limiter.day.waitFor(1); dayWait > wait {
+		wait = dayWait
+	}
+	if wait > limiter.MaxWait {
+		limiter.mutex.Unlock()
+		return &QuotaError{RetryAfter: wait}
+	}
+	limiter.minute.tokens--
+	limiter.day.tokens--
+	limiter.mutex.Unlock()
+	if wait > 0 {
+		limiter.sleep(wait)
+	}
+	return nil
+}
+
+// Вспомогательный метод пополняющий корзину токенами накопившимися с прошлого обновления
+func (bucket *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(bucket.updated)
+	if elapsed <= 0 {
+		return
+	}
+	bucket.tokens = math.Min(bucket.capacity, bucket.tokens+bucket.capacity*float64(elapsed)/float64(bucket.period))
+	bucket.updated = now
+}
+
+// Вспомогательный метод возвращающий время через которое в корзине накопится нужное количество токенов
+func (bucket *tokenBucket) waitFor(tokens float64) time.Duration {
+	missing := tokens - bucket.tokens
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(missing / bucket.capacity * float64(bucket.period)))
+}
+
+// Структура QuotaError возвращается когда лимит запросов к Alpha Vantage исчерпан
+type QuotaError struct {
+	RetryAfter time.Duration // через сколько можно повторить запрос
+}
+
+// Метод возвращающий текст ошибки с подсказкой когда повторить запрос
+func (quotaErr *QuotaError) Error() string {
+	return fmt.Sprintf("quotaExhausted, retry after %s", quotaErr.RetryAfter.Round(time.Second))
+}
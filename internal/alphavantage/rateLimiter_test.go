@@ -0,0 +1,69 @@
+package alphavantage
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// Вспомогательный метод создающий ограничитель с подмененными часами, ожидание сдвигает часы вперед
+func testRateLimiter(perMinute, perDay int, maxWait time.Duration) (*RateLimiter, *time.Time) {
+	limiter := NewRateLimiter(perMinute, perDay, maxWait)
+	now := time.Date(2020, 5, 12, 10, 0, 0, 0, time.UTC)
+	limiter.minute.updated, limiter.day.updated = now, now
+	limiter.now = func() time.Time { return now }
+	limiter.sleep = func(wait time.Duration) { now = now.Add(wait) }
+	return limiter, &now
+}
+
+func TestRateLimiterMinute(t *testing.T) {
+	limiter, now := testRateLimiter(5, 500, 30*time.Second)
+	start := *now
+	for i := 0; i < 5; i++ {
+		if err := limiter.Wait(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !now.Equal(start) {
+		t.Errorf("unexpected wait %s", now.Sub(start))
+	}
+	// шестой запрос ждет пополнения одного токена (12 секунд)
+	if err := limiter.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if wait := now.Sub(start); wait != 12*time.Second {
+		t.Errorf("wrong wait, want 12s, get %s", wait)
+	}
+}
+
+func TestRateLimiterQuotaError(t *testing.T) {
+	limiter, _ := testRateLimiter(1, 500, 10*time.Second)
+	if err := limiter.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	err := limiter.Wait()
+	var quotaErr *QuotaError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("wrong error %v", err)
+	}
+	if quotaErr.RetryAfter != time.Minute {
+		t.Errorf("wrong retry after, want 1m, get %s", quotaErr.RetryAfter)
+	}
+}
+
+func TestRateLimiterDay(t *testing.T) {
+	limiter, now := testRateLimiter(5, 2, time.Minute)
+	for i := 0; i < 2; i++ {
+		if err := limiter.Wait(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	*now = now.Add(time.Hour)
+	var quotaErr *QuotaError
+	if err := limiter.Wait(); !errors.As(err, &quotaErr) {
+		t.Fatalf("wrong error %v", err)
+	}
+	if quotaErr.RetryAfter != 11*time.Hour {
+		t.Errorf("wrong retry after, want 11h, get %s", quotaErr.RetryAfter)
+	}
+}
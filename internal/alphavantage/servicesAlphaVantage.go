@@ -0,0 +1,100 @@
+package alphavantage
+
+import (
+	"InvestmentHelpver_V2/internal/httpclient"
+
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Адрес API Alpha Vantage по умолчанию
+const DefaultBaseURL = "https://www.alphavantage.co"
+
+// Структура Client выполняет запросы к Alpha Vantage: выбирает ключ из пула, ждет своей очереди в ограничителе
+// и превращает сообщения Alpha Vantage об ошибках в ошибки Go
+type Client struct {
+	BaseURL    string       // адрес API, например https://www.alphavantage.co или адрес прокси
+	HTTPClient *http.Client // HTTP клиент с таймаутами, прокси и транспортом
+	Keys       *KeyPool     // пул ключей API
+	Limiter    *RateLimiter // ограничитель частоты запросов, nil - без ограничения
+}
+
+// Конструктор для структуры Client, пустой baseURL заменяется адресом по умолчанию, nil httpClient - клиентом httpclient.Default
+func NewClient(baseURL string, httpClient *http.Client, keys *KeyPool, limiter *RateLimiter) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if httpClient == nil {
+		httpClient = httpclient.Default
+	}
+	client := &Client{strings.TrimSuffix(baseURL, "/"), httpClient, keys, limiter}
+	return client
+}
+
+// Метод выполняющий запрос к функции Alpha Vantage с параметрами params и возвращающий тело JSON ответа.
+// Если ключ уперся в лимит, он отправляется на карантин и запрос повторяется со следующим ключом,
+// когда все ключи на карантине возвращается QuotaError
+func (client *Client) Query(params url.Values) ([]byte, error) {
+	return client.query(params, true)
+}
+
+// Метод выполняющий запрос к функции Alpha Vantage отвечающей в формате CSV (например LISTING_STATUS),
+// ошибки Alpha Vantage в таких ответах приходят в формате JSON и обрабатываются так же как в Query
+func (client *Client) QueryCSV(params url.Values) ([]byte, error) {
+	return client.query(params, false)
+}
+
+// Вспомогательный метод выполняющий запрос с ротацией ключей, jsonExpected - ожидается ли ответ в формате JSON
+func (client *Client) query(params url.Values, jsonExpected bool) ([]byte, error) {
+	for {
+		apiKey, err := client.Keys.Next()
+		if err != nil {
+			return nil, err
+		}
+		if client.Limiter != nil {
+			err = client.Limiter.Wait()
+			if err != nil {
+				return nil, err
+			}
+		}
+		body, err := client.get(params, apiKey)
+		if err == nil && (jsonExpected || strings.HasPrefix(strings.TrimSpace(string(body)), "{")) {
+			err = CheckResponse(body)
+		}
+		if errors.Is(err, ErrRateLimited) {
+			client.Keys.MarkThrottled(apiKey)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		client.Keys.MarkSuccess(apiKey)
+		return body, nil
+	}
+}
+
+// Вспомогательный метод выполняющий один HTTP запрос с указанным ключом
+func (client *Client) get(params url.Values, apiKey string) ([]byte, error) {
+	query := url.Values{}
+	for name, values := range params {
+		query[name] = values
+	}
+	query.Set("apikey", apiKey)
+	resp, err := client.HTTPClient.Get(client.BaseURL + "/query?" + query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %s", ErrMalformedResponse, resp.Status)
+	}
+	return body, nil
+}
@@ -0,0 +1,88 @@
+package alphavantage
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestClientQuery(t *testing.T) {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey := r.URL.Query().Get("apikey")
+		requests = append(requests, apiKey)
+		switch {
+		case r.URL.Path != "/query":
+			w.WriteHeader(http.StatusNotFound)
+		case apiKey == "THROTTLED":
+			fmt.Fprint(w, `{"Note": "Thank you for using Alpha Vantage! Our standard API call frequency is 5 calls per minute"}`)
+		case r.URL.Query().Get("symbol") == "unrealSymbol":
+			fmt.Fprint(w, `{"Error Message": "Invalid API call."}`)
+		default:
+			fmt.Fprint(w, `{"Global Quote": {}}`)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("test key rotation", func(t *testing.T) {
+		requests = nil
+		client := NewClient(server.URL+"/", server.Client(), NewKeyPool("THROTTLED", "GOOD"), nil)
+		body, err := client.Query(url.Values{"function": {"GLOBAL_QUOTE"}, "symbol": {"IBM"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != `{"Global Quote": {}}` || len(requests) != 2 {
+			t.Errorf("wrong result %s, requests %v", body, requests)
+		}
+		// ключ на карантине больше не используется
+		client.Query(url.Values{"function": {"GLOBAL_QUOTE"}, "symbol": {"IBM"}})
+		if requests[2] != "GOOD" {
+			t.Errorf("quarantined key used, requests %v", requests)
+		}
+	})
+
+	t.Run("test all keys throttled", func(t *testing.T) {
+		client := NewClient(server.URL, server.Client(), NewKeyPool("THROTTLED"), nil)
+		_, err := client.Query(url.Values{"function": {"GLOBAL_QUOTE"}})
+		var quotaErr *QuotaError
+		if !errors.As(err, &quotaErr) {
+			t.Errorf("wrong error %v", err)
+		}
+	})
+
+	t.Run("test unknown symbol", func(t *testing.T) {
+		client := NewClient(server.URL, server.Client(), NewKeyPool("GOOD"), nil)
+		_, err := client.Query(url.Values{"function": {"GLOBAL_QUOTE"}, "symbol": {"unrealSymbol"}})
+		if !errors.Is(err, ErrUnknownSymbol) {
+			t.Errorf("wrong error %v", err)
+		}
+	})
+
+	t.Run("test csv response", func(t *testing.T) {
+		csvServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "symbol,name\nIBM,International Business Machines\n")
+		}))
+		defer csvServer.Close()
+		client := NewClient(csvServer.URL, csvServer.Client(), NewKeyPool("GOOD"), nil)
+		body, err := client.QueryCSV(url.Values{"function": {"LISTING_STATUS"}})
+		if err != nil || len(body) == 0 {
+			t.Errorf("wrong result %s, error %v", body, err)
+		}
+	})
+}
+
+func TestClientTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, &http.Client{Timeout: 20 * time.Millisecond}, NewKeyPool("GOOD"), nil)
+	if _, err := client.Query(url.Values{"function": {"GLOBAL_QUOTE"}}); err == nil {
+		t.Error("expected timeout error")
+	}
+}
@@ -0,0 +1,239 @@
+package analytics
+
+import (
+	"InvestmentHelpver_V2/internal/plot"
+
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Структура Return содержит доходность за период от предыдущей свечи до свечи с датой Date (0.01 - 1%)
+type Return struct {
+	Date  time.Time
+	Value float64
+}
+
+// Структура Drawdown содержит просадку: падение от максимума Peak до минимума Trough (-0.3 - падение на 30%)
+// и дату Recovery когда цена вернулась к максимуму (nil - еще не вернулась)
+type Drawdown struct {
+	Value    float64
+	Peak     time.Time
+	Trough   time.Time
+	Recovery *time.Time `json:",omitempty"`
+}
+
+// Структура Stats содержит статистику доходности и риска графика за период
+type Stats struct {
+	Symbol               string    // символ финансового актива
+	Benchmark            string    `json:",omitempty"` // символ бенчмарка
+	From                 time.Time // дата первой свечи
+	To                   time.Time // дата последней свечи
+	Periods              int       // количество доходностей (свечей минус одна)
+	TotalReturn          float64   // доходность за весь период
+	AnnualizedReturn     float64   // среднегодовая доходность (сложный процент)
+	AnnualizedVolatility float64   // годовая волатильность (стандартное отклонение доходностей)
+	Sharpe               float64   // коэффициент Шарпа
+	Sortino              float64   // коэффициент Сортино
+	MaxDrawdown          Drawdown  // максимальная просадка
+	BestPeriod           Return    // лучшая доходность за одну свечу
+	WorstPeriod          Return    // худшая доходность за одну свечу
+	Beta                 *float64  `json:",omitempty"` // бета относительно бенчмарка, nil если бенчмарк не передан
+}
+
+// Количество торговых периодов в году для дневных свечей разных классов активов
+const (
+	TradingDaysEquity = 252
+	TradingDaysFX     = 260
+	TradingDaysCrypto = 365
+)
+
+var errNotEnoughCandles = errors.New("notEnoughCandles")
+
+// Метод возвращающий количество свечей указанного интервала в году, используется для перевода статистики в годовую
+func PeriodsPerYear(interval plot.Interval, assetClass plot.AssetClass) float64 {
+	days, sessionMinutes := float64(TradingDaysEquity), 390.0
+	switch assetClass {
+	case plot.AssetClassFX:
+		days, sessionMinutes = TradingDaysFX, 24*60
+	case plot.AssetClassCrypto:
+		days, sessionMinutes = TradingDaysCrypto, 24*60
+	}
+	switch interval {
+	case plot.IntervalWeekly:
+		return 52
+	case plot.IntervalMonthly:
+		return 12
+	}
+	if interval.IsIntraday() {
+		minutes, _ := strconv.Atoi(strings.TrimSuffix(string(interval), "min"))
+		return days * sessionMinutes / float64(minutes)
+	}
+	return days
+}
+
+// Метод возвращающий простые доходности между соседними свечами по ценам закрытия
+func Returns(candles []plot.Candle) []Return {
+	returns := []Return{}
+	for i := 1; i < len(candles); i++ {
+		if candles[i-1].Close == 0 {
+			continue
+		}
+		returns = append(returns, Return{candles[i].Date, candles[i].Close/candles[i-1].Close - 1})
+	}
+	return returns
+}
+
+// Метод возвращающий логарифмические доходности между соседними свечами по ценам закрытия
+func LogReturns(candles []plot.Candle) []Return {
+	returns := []Return{}
+	for i := 1; i < len(candles); i++ {
+		if candles[i-1].Close <= 0 || candles[i].Close <= 0 {
+			continue
+		}
+		returns = append(returns, Return{candles[i].Date, math.Log(candles[i].Close / candles[i-1].Close)})
+	}
+	return returns
+}
+
+// Метод находящий максимальную просадку по ценам закрытия
+func MaxDrawdown(candles []plot.Candle) Drawdown {
+	drawdown := Drawdown{}
+	if len(candles) == 0 {
+		return drawdown
+	}
+	peak := candles[0]
+	drawdown.Peak, drawdown.Trough = peak.Date, peak.Date
+	for _, candle := range candles {
+		if candle.Close >= peak.Close {
+			peak = candle
+		}
+		if peak.Close == 0 {
+			continue
+		}
+		if value := candle.Close/peak.Close - 1; value < drawdown.Value {
+			drawdown = Drawdown{Value: value, Peak: peak.Date, Trough: candle.Date}
+		}
+	}
+	if drawdown.Value == 0 {
+		return drawdown
+	}
+	peakClose := 0.0
+	for _, candle := range candles {
+		if candle.Date.Equal(drawdown.Peak) {
+			peakClose = candle.Close
+		}
+		if candle.Date.After(drawdown.Trough) && candle.Close >= peakClose {
+			recovery := candle.Date
+			drawdown.Recovery = &recovery
+			break
+		}
+	}
+	return drawdown
+}
+
+// Метод рассчитывающий бету графика относительно бенчмарка: ковариация доходностей деленная на дисперсию доходностей бенчмарка,
+// доходности считаются только по общим датам графиков
+func Beta(candles, benchmark []plot.Candle) (float64, error) {
+	aligned := plot.AlignByDate(candles, benchmark)
+	returns, benchmarkReturns := values(Returns(aligned[0])), values(Returns(aligned[1]))
+	if len(returns) < 2 || len(returns) != len(benchmarkReturns) {
+		return 0, errNotEnoughCandles
+	}
+	mean, benchmarkMean := average(returns), average(benchmarkReturns)
+	covariance, variance := 0.0, 0.0
+	for i := range returns {
+		covariance += (returns[i] - mean) * (benchmarkReturns[i] - benchmarkMean)
+		variance += (benchmarkReturns[i] - benchmarkMean) * (benchmarkReturns[i] - benchmarkMean)
+	}
+	if variance == 0 {
+		return 0, errors.New("zeroBenchmarkVariance")
+	}
+	return covariance / variance, nil
+}
+
+// Метод рассчитывающий статистику графика. periodsPerYear - количество свечей в году (см. PeriodsPerYear),
+// riskFree - годовая безрисковая ставка (0.02 - 2%), benchmark - график бенчмарка для расчета беты (nil - без беты).
+// Коэффициенты Шарпа и Сортино равны 0 если волатильность равна 0
+func Calculate(candles, benchmark []plot.Candle, periodsPerYear, riskFree float64) (Stats, error) {
+	returnsList := Returns(candles)
+	if len(returnsList) < 2 {
+		return Stats{}, errNotEnoughCandles
+	}
+	returns := values(returnsList)
+	first, last := candles[0], candles[len(candles)-1]
+	if first.Close == 0 {
+		return Stats{}, errors.New("zeroPrice")
+	}
+	stats := Stats{From: first.Date, To: last.Date, Periods: len(returns)}
+	stats.TotalReturn = last.Close/first.Close - 1
+	stats.AnnualizedReturn = math.Pow(1+stats.TotalReturn, periodsPerYear/float64(len(returns))) - 1
+
+	periodRiskFree := riskFree / periodsPerYear
+	excess := make([]float64, len(returns))
+	downside := 0.0
+	for i, value := range returns {
+		excess[i] = value - periodRiskFree
+		if excess[i] < 0 {
+			downside += excess[i] * excess[i]
+		}
+	}
+	deviation := standardDeviation(returns)
+	downsideDeviation := math.Sqrt(downside / float64(len(returns)))
+	stats.AnnualizedVolatility = deviation * math.Sqrt(periodsPerYear)
+	if deviation != 0 {
+		stats.Sharpe = average(excess) / deviation * math.Sqrt(periodsPerYear)
+	}
+	if downsideDeviation != 0 {
+		stats.Sortino = average(excess) / downsideDeviation * math.Sqrt(periodsPerYear)
+	}
+
+	stats.MaxDrawdown = MaxDrawdown(candles)
+	stats.BestPeriod, stats.WorstPeriod = returnsList[0], returnsList[0]
+	for _, value := range returnsList {
+		if value.Value > stats.BestPeriod.Value {
+			stats.BestPeriod = value
+		}
+		if value.Value < stats.WorstPeriod.Value {
+			stats.WorstPeriod = value
+		}
+	}
+	if benchmark != nil {
+		beta, err := Beta(candles, benchmark)
+		if err != nil {
+			return Stats{}, err
+		}
+		stats.Beta = &beta
+	}
+	return stats, nil
+}
+
+// Вспомогательный метод возвращающий значения доходностей без дат
+func values(returns []Return) []float64 {
+	result := make([]float64, len(returns))
+	for i, value := range returns {
+		result[i] = value.Value
+	}
+	return result
+}
+
+// Вспомогательный метод возвращающий среднее значение
+func average(values []float64) float64 {
+	sum := 0.0
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// Вспомогательный метод возвращающий выборочное стандартное отклонение
+func standardDeviation(values []float64) float64 {
+	mean := average(values)
+	sum := 0.0
+	for _, value := range values {
+		sum += (value - mean) * (value - mean)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
@@ -0,0 +1,119 @@
+package analytics
+
+import (
+	"InvestmentHelpver_V2/internal/plot"
+	"InvestmentHelpver_V2/internal/plot/plottest"
+
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestReturns(t *testing.T) {
+	candles := plottest.Candles(100, 110, 99)
+	returns := Returns(candles)
+	if len(returns) != 2 || math.Abs(returns[0].Value-0.1) > 1e-9 || math.Abs(returns[1].Value+0.1) > 1e-9 || !returns[1].Date.Equal(candles[2].Date) {
+		t.Errorf("wrong returns %+v", returns)
+	}
+	logReturns := LogReturns(candles)
+	if math.Abs(logReturns[0].Value-math.Log(1.1)) > 1e-9 {
+		t.Errorf("wrong log returns %+v", logReturns)
+	}
+}
+
+func TestMaxDrawdown(t *testing.T) {
+	candles := plottest.Candles(100, 120, 90, 60, 100, 130, 110)
+	drawdown := MaxDrawdown(candles)
+	if drawdown.Value != -0.5 || !drawdown.Peak.Equal(candles[1].Date) || !drawdown.Trough.Equal(candles[3].Date) {
+		t.Errorf("wrong drawdown %+v", drawdown)
+	}
+	if drawdown.Recovery == nil || !drawdown.Recovery.Equal(candles[5].Date) {
+		t.Errorf("wrong recovery %v", drawdown.Recovery)
+	}
+	if drawdown := MaxDrawdown(plottest.Candles(100, 80, 90)); drawdown.Recovery != nil || math.Abs(drawdown.Value+0.2) > 1e-9 {
+		t.Errorf("wrong drawdown %+v", drawdown)
+	}
+	if drawdown := MaxDrawdown(plottest.Candles(1, 2, 3)); drawdown.Value != 0 {
+		t.Errorf("wrong drawdown %+v", drawdown)
+	}
+}
+
+func TestBeta(t *testing.T) {
+	benchmark := plottest.Candles(100, 110, 99, 108.9)
+	// доходности в два раза больше доходностей бенчмарка
+	candles := plottest.Candles(100, 120, 96, 115.2)
+	beta, err := Beta(candles, benchmark)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(beta-2) > 1e-9 {
+		t.Errorf("wrong beta, want 2, get %f", beta)
+	}
+	if _, err := Beta(candles[:2], benchmark); err == nil {
+		t.Error("expected not enough candles error")
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	candles := plottest.Candles(100, 110, 99, 108.9)
+	stats, err := Calculate(candles, candles, 252, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	returns := []float64{0.1, -0.1, 0.1}
+	mean := (0.1 - 0.1 + 0.1) / 3
+	deviation := math.Sqrt(((0.1-mean)*(0.1-mean)*2 + (-0.1-mean)*(-0.1-mean)) / 2)
+	downside := math.Sqrt(0.01 / 3)
+	tests := []struct {
+		name string
+		get  float64
+		want float64
+	}{
+		{"total return", stats.TotalReturn, 0.089},
+		{"annualized return", stats.AnnualizedReturn, math.Pow(1.089, 252.0/3) - 1},
+		{"volatility", stats.AnnualizedVolatility, deviation * math.Sqrt(252)},
+		{"sharpe", stats.Sharpe, mean / deviation * math.Sqrt(252)},
+		{"sortino", stats.Sortino, mean / downside * math.Sqrt(252)},
+		{"drawdown", stats.MaxDrawdown.Value, -0.1},
+		{"best", stats.BestPeriod.Value, returns[0]},
+		{"worst", stats.WorstPeriod.Value, returns[1]},
+		{"beta", *stats.Beta, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if math.Abs(test.get-test.want) > 1e-6*math.Max(1, math.Abs(test.want)) {
+				t.Errorf("wrong value, want %f, get %f", test.want, test.get)
+			}
+		})
+	}
+	if stats.Periods != 3 || !stats.From.Equal(candles[0].Date) || !stats.To.Equal(candles[3].Date) {
+		t.Errorf("wrong period %+v", stats)
+	}
+
+	if stats, err := Calculate(plottest.Candles(1, 1, 1), nil, 252, 0.02); err != nil || stats.Sharpe != 0 || stats.Beta != nil {
+		t.Errorf("wrong flat stats %+v, error %v", stats, err)
+	}
+	if _, err := Calculate(plottest.Candles(1, 2), nil, 252, 0); err == nil {
+		t.Error("expected not enough candles error")
+	}
+}
+
+func TestPeriodsPerYear(t *testing.T) {
+	tests := []struct {
+		interval   plot.Interval
+		assetClass plot.AssetClass
+		want       float64
+	}{
+		{plot.IntervalDaily, plot.AssetClassEquity, 252},
+		{plot.IntervalDaily, plot.AssetClassCrypto, 365},
+		{plot.IntervalWeekly, plot.AssetClassFX, 52},
+		{plot.Interval30Min, plot.AssetClassEquity, 252 * 13},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test %s %s", test.interval, test.assetClass), func(t *testing.T) {
+			if got := PeriodsPerYear(test.interval, test.assetClass); got != test.want {
+				t.Errorf("wrong periods, want %f, get %f", test.want, got)
+			}
+		})
+	}
+}
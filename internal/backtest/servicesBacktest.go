@@ -0,0 +1,163 @@
+package backtest
+
+import (
+	"InvestmentHelpver_V2/internal/analytics"
+	"InvestmentHelpver_V2/internal/plot"
+
+	"errors"
+	"math"
+	"time"
+)
+
+// Начальный капитал по умолчанию
+const DefaultCapital = 10000
+
+// Структура Config содержит параметры симуляции сделок
+type Config struct {
+	InitialCapital float64 // начальный капитал, 0 - DefaultCapital
+	Commission     float64 // комиссия за сделку доля от суммы сделки (0.001 - 0.1%)
+	Slippage       float64 // проскальзывание доля от цены, ухудшает цену покупки и продажи (0.0005 - 0.05%)
+}
+
+// Структура Trade содержит сделку: покупку по цене EntryPrice и продажу по цене ExitPrice с учетом проскальзывания,
+// позиция не закрытая к концу графика оценивается по последней цене закрытия и помечается Open
+type Trade struct {
+	Entry      time.Time
+	EntryPrice float64
+	Exit       time.Time
+	ExitPrice  float64
+	Shares     float64
+	Commission float64 // комиссия за покупку и продажу
+	Profit     float64 // прибыль за вычетом комиссий
+	Return     float64 // доходность сделки относительно вложенной суммы с комиссией
+	Open       bool
+}
+
+// Структура EquityPoint содержит стоимость счета (деньги и позиция по цене закрытия) на дату свечи
+type EquityPoint struct {
+	Date  time.Time
+	Value float64
+}
+
+// Структура Result содержит результат симуляции стратегии на графике
+type Result struct {
+	Symbol         string `json:",omitempty"`
+	Strategy       string
+	From           time.Time // дата первой свечи
+	To             time.Time // дата последней свечи
+	InitialCapital float64
+	FinalEquity    float64
+	TotalReturn    float64            // доходность за весь период
+	CAGR           float64            // среднегодовая доходность по календарным дням
+	MaxDrawdown    analytics.Drawdown // максимальная просадка стоимости счета
+	WinRate        float64            // доля прибыльных сделок среди всех сделок, включая открытую
+	Trades         []Trade
+	Equity         []EquityPoint
+}
+
+var errWrongConfig = errors.New("wrongConfig")
+var errNotEnoughCandles = errors.New("notEnoughCandles")
+
+// Метод симулирующий торговлю по сигналам стратегии: сигнал на закрытии свечи исполняется по цене открытия следующей свечи,
+// покупка производится на весь доступный капитал (дробное количество), продажа закрывает всю позицию
+func Run(candles []plot.Candle, strategy Strategy, config Config) (Result, error) {
+	if config.InitialCapital == 0 {
+		config.InitialCapital = DefaultCapital
+	}
+	if config.InitialCapital < 0 || config.Commission < 0 || config.Commission >= 1 || config.Slippage < 0 || config.Slippage >= 1 {
+		return Result{}, errWrongConfig
+	}
+	if len(candles) < 2 {
+		return Result{}, errNotEnoughCandles
+	}
+	signals, err := strategy.Signals(candles)
+	if err != nil {
+		return Result{}, err
+	}
+	if len(signals) != len(candles) {
+		return Result{}, errWrongStrategy
+	}
+
+	result := Result{
+		Strategy:       strategy.Name(),
+		From:           candles[0].Date,
+		To:             candles[len(candles)-1].Date,
+		InitialCapital: config.InitialCapital,
+		Trades:         []Trade{},
+		Equity:         make([]EquityPoint, 0, len(candles)),
+	}
+	cash, cost := config.InitialCapital, 0.0
+	var trade *Trade
+	for i, candle := range candles {
+		if i > 0 {
+			switch {
+			case signals[i-1] == SignalBuy && trade == nil && cash > 0:
+				price := candle.Open * (1 + config.Slippage)
+				shares := cash / (price * (1 + config.Commission))
+				commission := shares * price * config.Commission
+				trade = &Trade{Entry: candle.Date, EntryPrice: price, Shares: shares, Commission: commission}
+				cost, cash = cash, 0
+			case signals[i-1] == SignalSell && trade != nil:
+				price := candle.Open * (1 - config.Slippage)
+				value := trade.Shares * price
+				commission := value * config.Commission
+				cash = value - commission
+				result.Trades = append(result.Trades, closeTrade(*trade, candle.Date, price, commission, cash, cost))
+				trade = nil
+			}
+		}
+		equity := cash
+		if trade != nil {
+			equity += trade.Shares * candle.Close
+		}
+		result.Equity = append(result.Equity, EquityPoint{Date: candle.Date, Value: equity})
+	}
+	if trade != nil {
+		last := candles[len(candles)-1]
+		open := closeTrade(*trade, last.Date, last.Close, 0, trade.Shares*last.Close, cost)
+		open.Open = true
+		result.Trades = append(result.Trades, open)
+	}
+
+	result.FinalEquity = result.Equity[len(result.Equity)-1].Value
+	if result.InitialCapital > 0 {
+		result.TotalReturn = result.FinalEquity/result.InitialCapital - 1
+	}
+	years := result.To.Sub(result.From).Hours() / 24 / 365.25
+	if years > 0 && result.TotalReturn > -1 {
+		result.CAGR = math.Pow(1+result.TotalReturn, 1/years) - 1
+	}
+	result.MaxDrawdown = analytics.MaxDrawdown(equityCandles(result.Equity))
+	wins := 0
+	for _, trade := range result.Trades {
+		if trade.Profit > 0 {
+			wins++
+		}
+	}
+	if len(result.Trades) > 0 {
+		result.WinRate = float64(wins) / float64(len(result.Trades))
+	}
+	return result, nil
+}
+
+// Вспомогательный метод заполняющий результат сделки, proceeds - сумма полученная при продаже за вычетом комиссии,
+// cost - сумма потраченная на покупку вместе с комиссией
+func closeTrade(trade Trade, date time.Time, price, commission, proceeds, cost float64) Trade {
+	trade.Exit = date
+	trade.ExitPrice = price
+	trade.Commission += commission
+	trade.Profit = proceeds - cost
+	if cost > 0 {
+		trade.Return = trade.Profit / cost
+	}
+	return trade
+}
+
+// Вспомогательный метод представляющий кривую стоимости счета свечами с ценой закрытия равной стоимости
+func equityCandles(equity []EquityPoint) []plot.Candle {
+	candles := make([]plot.Candle, len(equity))
+	for i, point := range equity {
+		candles[i] = plot.Candle{Date: point.Date, Open: point.Value, High: point.Value, Low: point.Value, Close: point.Value}
+	}
+	return candles
+}
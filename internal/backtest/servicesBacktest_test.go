@@ -0,0 +1,129 @@
+package backtest
+
+import (
+	"InvestmentHelpver_V2/internal/plot"
+	"InvestmentHelpver_V2/internal/plot/plottest"
+
+	"math"
+	"testing"
+)
+
+// Вспомогательный метод создающий дневные свечи из цен открытия и закрытия
+func testCandles(prices ...[2]float64) []plot.Candle {
+	ohlc := make([][4]float64, len(prices))
+	for i, price := range prices {
+		ohlc[i] = [4]float64{price[0], math.Max(price[0], price[1]), math.Min(price[0], price[1]), price[1]}
+	}
+	return plottest.OHLC(ohlc...)
+}
+
+// Стратегия с заранее заданными сигналами
+type testStrategy struct {
+	signals []Signal
+	err     error
+}
+
+func (strategy testStrategy) Name() string {
+	return "test"
+}
+
+func (strategy testStrategy) Signals(candles []plot.Candle) ([]Signal, error) {
+	return strategy.signals, strategy.err
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestRun(t *testing.T) {
+	candles := testCandles([2]float64{9, 10}, [2]float64{10, 11}, [2]float64{11, 9}, [2]float64{12, 13}, [2]float64{13, 14})
+	strategy := testStrategy{signals: []Signal{SignalBuy, SignalBuy, SignalSell, SignalNone, SignalNone}}
+	result, err := Run(candles, strategy, Config{InitialCapital: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Trades) != 1 {
+		t.Fatalf("wrong trades %+v", result.Trades)
+	}
+	trade := result.Trades[0]
+	if !trade.Entry.Equal(candles[1].Date) || !trade.Exit.Equal(candles[3].Date) || trade.EntryPrice != 10 || trade.ExitPrice != 12 ||
+		trade.Shares != 100 || !almostEqual(trade.Profit, 200) || !almostEqual(trade.Return, 0.2) || trade.Open {
+		t.Errorf("wrong trade %+v", trade)
+	}
+	wantEquity := []float64{1000, 1100, 900, 1200, 1200}
+	for i, point := range result.Equity {
+		if !almostEqual(point.Value, wantEquity[i]) || !point.Date.Equal(candles[i].Date) {
+			t.Errorf("wrong equity %d: %+v", i, point)
+		}
+	}
+	if !almostEqual(result.FinalEquity, 1200) || !almostEqual(result.TotalReturn, 0.2) || result.WinRate != 1 || result.Strategy != "test" {
+		t.Errorf("wrong result %+v", result)
+	}
+	if !almostEqual(result.MaxDrawdown.Value, 900.0/1100-1) || !result.MaxDrawdown.Trough.Equal(candles[2].Date) {
+		t.Errorf("wrong drawdown %+v", result.MaxDrawdown)
+	}
+	years := 4.0 / 365.25
+	if !almostEqual(result.CAGR, math.Pow(1.2, 1/years)-1) {
+		t.Errorf("wrong CAGR %f", result.CAGR)
+	}
+}
+
+func TestRunCosts(t *testing.T) {
+	candles := testCandles([2]float64{9, 10}, [2]float64{10, 11}, [2]float64{11, 9}, [2]float64{12, 13})
+	strategy := testStrategy{signals: []Signal{SignalBuy, SignalNone, SignalSell, SignalNone}}
+	result, err := Run(candles, strategy, Config{InitialCapital: 1000, Commission: 0.01, Slippage: 0.01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trade := result.Trades[0]
+	shares := 1000 / (10.1 * 1.01)
+	proceeds := shares * 11.88 * 0.99
+	if !almostEqual(trade.EntryPrice, 10.1) || !almostEqual(trade.ExitPrice, 11.88) || !almostEqual(trade.Shares, shares) ||
+		!almostEqual(trade.Commission, shares*10.1*0.01+shares*11.88*0.01) || !almostEqual(trade.Profit, proceeds-1000) {
+		t.Errorf("wrong trade %+v", trade)
+	}
+	if !almostEqual(result.FinalEquity, proceeds) {
+		t.Errorf("wrong final equity %f", result.FinalEquity)
+	}
+}
+
+func TestRunOpenPosition(t *testing.T) {
+	candles := testCandles([2]float64{9, 10}, [2]float64{10, 8}, [2]float64{8, 7})
+	result, err := Run(candles, BuyAndHold{}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.InitialCapital != DefaultCapital || len(result.Trades) != 1 {
+		t.Fatalf("wrong result %+v", result)
+	}
+	trade := result.Trades[0]
+	if !trade.Open || trade.ExitPrice != 7 || !almostEqual(trade.Return, -0.3) || result.WinRate != 0 {
+		t.Errorf("wrong open trade %+v", trade)
+	}
+	if !almostEqual(result.TotalReturn, -0.3) {
+		t.Errorf("wrong total return %f", result.TotalReturn)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	candles := testCandles([2]float64{9, 10}, [2]float64{10, 11})
+	tests := []struct {
+		name     string
+		candles  []plot.Candle
+		strategy Strategy
+		config   Config
+		err      string
+	}{
+		{"negative capital", candles, BuyAndHold{}, Config{InitialCapital: -1}, "wrongConfig"},
+		{"commission", candles, BuyAndHold{}, Config{Commission: 1}, "wrongConfig"},
+		{"slippage", candles, BuyAndHold{}, Config{Slippage: -0.1}, "wrongConfig"},
+		{"one candle", candles[:1], BuyAndHold{}, Config{}, "notEnoughCandles"},
+		{"wrong signals", candles, testStrategy{signals: []Signal{SignalBuy}}, Config{}, "wrongStrategy"},
+		{"short sma", candles, SMACross{Fast: 2, Slow: 5}, Config{}, "notEnoughCandles"},
+	}
+	for _, test := range tests {
+		if _, err := Run(test.candles, test.strategy, test.config); err == nil || err.Error() != test.err {
+			t.Errorf("%s: wrong error %v", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package backtest
+
+import (
+	"InvestmentHelpver_V2/internal/indicators"
+	"InvestmentHelpver_V2/internal/plot"
+
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Сигнал стратегии на закрытии свечи, исполняется по цене открытия следующей свечи
+type Signal int
+
+const (
+	SignalNone Signal = iota
+	SignalBuy
+	SignalSell
+)
+
+// Интерфейс для торговой стратегии, Signals возвращает сигнал для каждой свечи графика (длина равна длине candles),
+// стратегия только открывает и закрывает длинную позицию на весь капитал, повторные сигналы игнорируются
+type Strategy interface {
+	Name() string
+	Signals(candles []plot.Candle) ([]Signal, error)
+}
+
+// Названия встроенных стратегий
+const (
+	StrategyBuyAndHold = "buyAndHold"
+	StrategySMACross   = "smaCross"
+	StrategyRSI        = "rsi"
+)
+
+// Параметры стратегии RSI по умолчанию
+const (
+	DefaultOversold   = 30
+	DefaultOverbought = 70
+)
+
+var errWrongStrategy = errors.New("wrongStrategy")
+
+// Структура StrategySpec содержит описание встроенной стратегии в запросе (Type: buyAndHold, smaCross или rsi),
+// параметры не относящиеся к стратегии игнорируются, нулевые значения заменяются значениями по умолчанию
+type StrategySpec struct {
+	Type       string
+	Fast       int     // период быстрой SMA для smaCross
+	Slow       int     // период медленной SMA для smaCross
+	Period     int     // период RSI для rsi
+	Oversold   float64 // уровень RSI ниже которого стратегия покупает
+	Overbought float64 // уровень RSI выше которого стратегия продает
+}
+
+// Метод создающий встроенную стратегию по ее описанию
+func NewStrategy(spec StrategySpec) (Strategy, error) {
+	switch strings.ToLower(spec.Type) {
+	case strings.ToLower(StrategyBuyAndHold):
+		return BuyAndHold{}, nil
+	case strings.ToLower(StrategySMACross):
+		if spec.Fast <= 0 || spec.Slow <= spec.Fast {
+			return nil, errWrongStrategy
+		}
+		return SMACross{Fast: spec.Fast, Slow: spec.Slow}, nil
+	case strings.ToLower(StrategyRSI):
+		strategy := RSIThreshold{Period: spec.Period, Oversold: spec.Oversold, Overbought: spec.Overbought}
+		if strategy.Period == 0 {
+			strategy.Period = indicators.DefaultRSIPeriod
+		}
+		if strategy.Oversold == 0 {
+			strategy.Oversold = DefaultOversold
+		}
+		if strategy.Overbought == 0 {
+			strategy.Overbought = DefaultOverbought
+		}
+		if strategy.Period < 0 || strategy.Oversold < 0 || strategy.Oversold >= strategy.Overbought || strategy.Overbought > 100 {
+			return nil, errWrongStrategy
+		}
+		return strategy, nil
+	}
+	return nil, errWrongStrategy
+}
+
+// Структура BuyAndHold описывает стратегию покупки на первой свече и удержания позиции до конца графика
+type BuyAndHold struct{}
+
+// Метод возвращающий название стратегии
+func (strategy BuyAndHold) Name() string {
+	return StrategyBuyAndHold
+}
+
+// Метод возвращающий сигнал на покупку на первой свече
+func (strategy BuyAndHold) Signals(candles []plot.Candle) ([]Signal, error) {
+	signals := make([]Signal, len(candles))
+	if len(signals) > 0 {
+		signals[0] = SignalBuy
+	}
+	return signals, nil
+}
+
+// Структура SMACross описывает стратегию пересечения скользящих средних: покупка когда быстрая SMA
+// пересекает медленную снизу вверх, продажа когда сверху вниз
+type SMACross struct {
+	Fast int
+	Slow int
+}
+
+// Метод возвращающий название стратегии с периодами
+func (strategy SMACross) Name() string {
+	return fmt.Sprintf("%s(%d,%d)", StrategySMACross, strategy.Fast, strategy.Slow)
+}
+
+// Метод возвращающий сигналы на свечах где быстрая SMA пересекает медленную
+func (strategy SMACross) Signals(candles []plot.Candle) ([]Signal, error) {
+	fast, err := indicators.SMA(candles, strategy.Fast)
+	if err != nil {
+		return nil, err
+	}
+	slow, err := indicators.SMA(candles, strategy.Slow)
+	if err != nil {
+		return nil, err
+	}
+	// значение SMA с периодом p на свече i находится в срезе под индексом i-p+1
+	difference := func(i int) float64 {
+		return fast[i-strategy.Fast+1].Value - slow[i-strategy.Slow+1].Value
+	}
+	signals := make([]Signal, len(candles))
+	for i := strategy.Slow; i < len(candles); i++ {
+		previous, current := difference(i-1), difference(i)
+		switch {
+		case previous <= 0 && current > 0:
+			signals[i] = SignalBuy
+		case previous >= 0 && current < 0:
+			signals[i] = SignalSell
+		}
+	}
+	return signals, nil
+}
+
+// Структура RSIThreshold описывает стратегию по уровням RSI: покупка когда RSI ниже Oversold, продажа когда выше Overbought
+type RSIThreshold struct {
+	Period     int
+	Oversold   float64
+	Overbought float64
+}
+
+// Метод возвращающий название стратегии с параметрами
+func (strategy RSIThreshold) Name() string {
+	return fmt.Sprintf("%s(%d,%g,%g)", StrategyRSI, strategy.Period, strategy.Oversold, strategy.Overbought)
+}
+
+// Метод возвращающий сигналы на свечах где RSI выходит за уровни
+func (strategy RSIThreshold) Signals(candles []plot.Candle) ([]Signal, error) {
+	rsi, err := indicators.RSI(candles, strategy.Period)
+	if err != nil {
+		return nil, err
+	}
+	signals := make([]Signal, len(candles))
+	// значение RSI на свече i находится в срезе под индексом i-period
+	for j, point := range rsi {
+		switch {
+		case point.Value < strategy.Oversold:
+			signals[j+strategy.Period] = SignalBuy
+		case point.Value > strategy.Overbought:
+			signals[j+strategy.Period] = SignalSell
+		}
+	}
+	return signals, nil
+}
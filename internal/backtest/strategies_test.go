@@ -0,0 +1,76 @@
+package backtest
+
+import (
+	"InvestmentHelpver_V2/internal/plot/plottest"
+
+	"testing"
+)
+
+func TestNewStrategy(t *testing.T) {
+	tests := []struct {
+		spec StrategySpec
+		name string
+		err  bool
+	}{
+		{StrategySpec{Type: "buyAndHold"}, "buyAndHold", false},
+		{StrategySpec{Type: "SMACROSS", Fast: 10, Slow: 30}, "smaCross(10,30)", false},
+		{StrategySpec{Type: "smaCross", Fast: 30, Slow: 10}, "", true},
+		{StrategySpec{Type: "smaCross"}, "", true},
+		{StrategySpec{Type: "rsi"}, "rsi(14,30,70)", false},
+		{StrategySpec{Type: "rsi", Period: 7, Oversold: 20, Overbought: 80}, "rsi(7,20,80)", false},
+		{StrategySpec{Type: "rsi", Oversold: 80, Overbought: 20}, "", true},
+		{StrategySpec{Type: "rsi", Period: -1}, "", true},
+		{StrategySpec{Type: "macd"}, "", true},
+		{StrategySpec{}, "", true},
+	}
+	for _, test := range tests {
+		strategy, err := NewStrategy(test.spec)
+		if (err != nil) != test.err {
+			t.Errorf("NewStrategy(%+v) error %v", test.spec, err)
+			continue
+		}
+		if err == nil && strategy.Name() != test.name {
+			t.Errorf("wrong name %s, want %s", strategy.Name(), test.name)
+		}
+	}
+}
+
+func TestSMACrossSignals(t *testing.T) {
+	candles := plottest.Candles(5, 4, 3, 2, 3, 4, 5, 6, 5, 4, 3, 2)
+	signals, err := SMACross{Fast: 2, Slow: 3}.Signals(candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]Signal{5: SignalBuy, 9: SignalSell}
+	for i, signal := range signals {
+		if signal != want[i] {
+			t.Errorf("wrong signal %d on candle %d", signal, i)
+		}
+	}
+}
+
+func TestRSIThresholdSignals(t *testing.T) {
+	candles := plottest.Candles(10, 9, 8, 7, 8, 9, 10, 11, 12)
+	signals, err := RSIThreshold{Period: 2, Oversold: 30, Overbought: 70}.Signals(candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signals) != len(candles) {
+		t.Fatalf("wrong signals length %d", len(signals))
+	}
+	for i := 0; i < 2; i++ {
+		if signals[i] != SignalNone {
+			t.Errorf("signal before first RSI value on candle %d", i)
+		}
+	}
+	if signals[2] != SignalBuy || signals[3] != SignalBuy || signals[8] != SignalSell {
+		t.Errorf("wrong signals %v", signals)
+	}
+}
+
+func TestBuyAndHoldSignals(t *testing.T) {
+	signals, _ := BuyAndHold{}.Signals(plottest.Candles(1, 2, 3))
+	if signals[0] != SignalBuy || signals[1] != SignalNone || signals[2] != SignalNone {
+		t.Errorf("wrong signals %v", signals)
+	}
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// интерфейс хранилища кэша, реализующие его структуры должны уметь возвращать значение по ключу
+// и сохранять значение по ключу на время ttl
+type Backend interface {
+	Get(string) ([]byte, bool, error)        // принимает ключ, возвращает значение и true если оно есть и не устарело
+	Set(string, []byte, time.Duration) error // принимает ключ, значение и время жизни значения
+}
+
+// Реализация интерфейса Backend, хранит значения в памяти и вытесняет давно не использовавшиеся при превышении размера
+type BackendLRU struct {
+	mutex   sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List       // от недавно использованных к давно использованным
+	now     func() time.Time // текущее время, подменяется в тестах
+}
+
+// Запись кэша в памяти
+type entryLRU struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// Конструктор для структуры BackendLRU, принимает максимальное количество записей
+func NewBackendLRU(size int) Backend {
+	backend := &BackendLRU{size: size, entries: map[string]*list.Element{}, order: list.New(), now: time.Now}
+	return backend
+}
+
+// Метод структуры BackendLRU, принимает ключ, возвращает значение и true если оно есть и не устарело
+func (backend *BackendLRU) Get(key string) ([]byte, bool, error) {
+	backend.mutex.Lock()
+	defer backend.mutex.Unlock()
+	element, ok := backend.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*entryLRU)
+	if !backend.now().Before(entry.expires) {
+		backend.order.Remove(element)
+		delete(backend.entries, key)
+		return nil, false, nil
+	}
+	backend.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+// Метод структуры BackendLRU, принимает ключ, значение и время жизни, вытесняет самую старую запись если кэш заполнен
+func (backend *BackendLRU) Set(key string, value []byte, ttl time.Duration) error {
+	backend.mutex.Lock()
+	defer backend.mutex.Unlock()
+	expires := backend.now().Add(ttl)
+	if element, ok := backend.entries[key]; ok {
+		entry := element.Value.(*entryLRU)
+		entry.value, entry.expires = value, expires
+		backend.order.MoveToFront(element)
+		return nil
+	}
+	backend.entries[key] = backend.order.PushFront(&entryLRU{key, value, expires})
+	for backend.order.Len() > backend.size {
+		oldest := backend.order.Back()
+		backend.order.Remove(oldest)
+		delete(backend.entries, oldest.Value.(*entryLRU).key)
+	}
+	return nil
+}
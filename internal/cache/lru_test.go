@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackendLRU(t *testing.T) {
+	backend := NewBackendLRU(2).(*BackendLRU)
+	now := time.Date(2020, 5, 12, 10, 0, 0, 0, time.UTC)
+	backend.now = func() time.Time { return now }
+
+	t.Run("test eviction", func(t *testing.T) {
+		backend.Set("a", []byte("1"), time.Hour)
+		backend.Set("b", []byte("2"), time.Hour)
+		backend.Get("a")
+		backend.Set("c", []byte("3"), time.Hour)
+		if _, ok, _ := backend.Get("b"); ok {
+			t.Error("least recently used entry not evicted")
+		}
+		if value, ok, _ := backend.Get("a"); !ok || string(value) != "1" {
+			t.Error("recently used entry evicted")
+		}
+	})
+
+	t.Run("test expiration", func(t *testing.T) {
+		backend.Set("d", []byte("4"), time.Minute)
+		now = now.Add(time.Minute)
+		if _, ok, _ := backend.Get("d"); ok {
+			t.Error("expired entry returned")
+		}
+	})
+}
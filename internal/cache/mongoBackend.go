@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"InvestmentHelpver_V2/internal/db"
+
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Структура entryMongo - запись кэша в MongoDB
+type entryMongo struct {
+	Key     string    `bson:"_id"`     // ключ кэша
+	Value   []byte    `bson:"value"`   // сохраненное значение
+	Expires time.Time `bson:"expires"` // момент после которого значение устарело
+}
+
+// Реализация интерфейса Backend, хранит значения в коллекции MongoDB и переживает перезапуск сервера
+type BackendMongo struct {
+	DBCollection *mongo.Collection //коллекция mongodb в которую записываются данные
+}
+
+// Конструктор для структуры BackendMongo, создает TTL индекс чтобы MongoDB сама удаляла устаревшие записи
+func NewBackendMongo(dbName, collectionName, dbServer string) (Backend, error) {
+	collection, _, err := db.GetCollection(dbName, collectionName, dbServer)
+	if err != nil {
+		return nil, err
+	}
+	index := mongo.IndexModel{Keys: bson.M{"expires": 1}, Options: options.Index().SetExpireAfterSeconds(0)}
+	_, err = collection.Indexes().CreateOne(context.TODO(), index)
+	if err != nil {
+		return nil, err
+	}
+	backend := BackendMongo{collection}
+	return backend, nil
+}
+
+// Метод структуры BackendMongo, принимает ключ, возвращает значение и true если оно есть и не устарело
+func (backend BackendMongo) Get(key string) ([]byte, bool, error) {
+	var entry entryMongo
+	err := backend.DBCollection.FindOne(context.TODO(), bson.M{"_id": key}).Decode(&entry)
+	if err == mongo.ErrNoDocuments {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if !time.Now().Before(entry.Expires) {
+		return nil, false, nil
+	}
+	return entry.Value, true, nil
+}
+
+// Метод структуры BackendMongo, принимает ключ, значение и время жизни, перезаписывает существующее значение
+func (backend BackendMongo) Set(key string, value []byte, ttl time.Duration) error {
+	entry := entryMongo{key, value, time.Now().Add(ttl)}
+	_, err := backend.DBCollection.ReplaceOne(context.TODO(), bson.M{"_id": key}, entry, options.Replace().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"InvestmentHelpver_V2/internal/news"
+	"InvestmentHelpver_V2/internal/plot"
+
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Структура TTL содержит время жизни записей кэша по видам данных
+type TTL struct {
+	News     time.Duration // время жизни новостей
+	Intraday time.Duration // время жизни внутридневных свечей
+	// дневные, недельные и месячные свечи живут до конца текущей дневной свечи (plot.NextDailyClose)
+}
+
+// Реализация интерфейсов plot.PlotManager и news.NewsManager, кэширует ответы других менеджеров в хранилище Backend.
+// Одновременные одинаковые запросы объединяются, поэтому источник получает только один из них
+type CacheManager struct {
+	PlotManager plot.PlotManager // менеджер графиков ответы которого кэшируются, может быть nil
+	NewsManager news.NewsManager // менеджер новостей ответы которого кэшируются, может быть nil
+	Backend     Backend          // хранилище кэша
+	TTL         TTL              // время жизни записей
+
+	group *group
+	now   func() time.Time // текущее время, подменяется в тестах
+}
+
+// Запись кэша графика, хранит название поставщика если PlotManager его сообщает
+type plotEntry struct {
+	Candles []plot.Candle
+	Source  string
+}
+
+// Конструктор для структуры CacheManager
+func NewCacheManager(plotManager plot.PlotManager, newsManager news.NewsManager, backend Backend, ttl TTL) *CacheManager {
+	cacheManager := &CacheManager{plotManager, newsManager, backend, ttl, &group{}, time.Now}
+	return cacheManager
+}
+
+// Метод структуры CacheManager, принимает символ финансового актива и параметры графика, возвращает список экземпляров структуры Candle
+func (cacheManager *CacheManager) GetPlot(symbol string, options plot.PlotOptions) ([]plot.Candle, error) {
+	candles, _, err := cacheManager.GetPlotWithSource(symbol, options)
+	return candles, err
+}
+
+// Метод структуры CacheManager, принимает символ финансового актива и параметры графика,
+// возвращает список экземпляров структуры Candle и название поставщика (пустое если PlotManager его не сообщает)
+func (cacheManager *CacheManager) GetPlotWithSource(symbol string, options plot.PlotOptions) ([]plot.Candle, string, error) {
+	key := plotKey(symbol, options)
+	var entry plotEntry
+	if cacheManager.load(key, &entry) {
+		return entry.Candles, entry.Source, nil
+	}
+	value, err := cacheManager.group.Do(key, func() (interface{}, error) {
+		entry := plotEntry{}
+		var err error
+		if sourced, ok := cacheManager.PlotManager.(plot.SourcedPlotManager); ok {
+			entry.Candles, entry.Source, err = sourced.GetPlotWithSource(symbol, options)
+		} else {
+			entry.Candles, err = cacheManager.PlotManager.GetPlot(symbol, options)
+		}
+		if err != nil {
+			return nil, err
+		}
+		cacheManager.store(key, entry, cacheManager.plotTTL(symbol, options))
+		return entry, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	entry = value.(plotEntry)
+	return entry.Candles, entry.Source, nil
+}
+
+// Метод структуры CacheManager, принимает символ финансового актива, возвращает список экземпляров структуры News
+func (cacheManager *CacheManager) GetNews(symbol string) ([]news.News, error) {
+	key := "news|" + symbol
+	var newsSlice []news.News
+	if cacheManager.load(key, &newsSlice) {
+		return newsSlice, nil
+	}
+	value, err := cacheManager.group.Do(key, func() (interface{}, error) {
+		newsSlice, err := cacheManager.NewsManager.GetNews(symbol)
+		if err != nil {
+			return nil, err
+		}
+		cacheManager.store(key, newsSlice, cacheManager.TTL.News)
+		return newsSlice, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.([]news.News), nil
+}
+
+// Вспомогательный метод возвращающий время жизни графика: внутридневные свечи живут TTL.Intraday, остальные до конца
+// дневной свечи своего класса актива (акции - до закрытия NYSE, валютные пары и криптовалюты - до полуночи UTC)
+func (cacheManager *CacheManager) plotTTL(symbol string, options plot.PlotOptions) time.Duration {
+	if options.Interval.IsIntraday() {
+		return cacheManager.TTL.Intraday
+	}
+	now := cacheManager.now()
+	return plot.NextDailyClose(now, plot.ResolveAssetClass(symbol, options.AssetClass)).Sub(now)
+}
+
+// Вспомогательный метод читающий значение из хранилища, ошибки хранилища не мешают запросу и только записываются в лог
+func (cacheManager *CacheManager) load(key string, value interface{}) bool {
+	data, ok, err := cacheManager.Backend.Get(key)
+	if err != nil {
+		log.Printf("cache get %s: %v\n", key, err)
+		return false
+	}
+	if !ok {
+		return false
+	}
+	err = json.Unmarshal(data, value)
+	if err != nil {
+		log.Printf("cache decode %s: %v\n", key, err)
+		return false
+	}
+	return true
+}
+
+// Вспомогательный метод сохраняющий значение в хранилище, ошибки хранилища только записываются в лог
+func (cacheManager *CacheManager) store(key string, value interface{}, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("cache encode %s: %v\n", key, err)
+		return
+	}
+	err = cacheManager.Backend.Set(key, data, ttl)
+	if err != nil {
+		log.Printf("cache set %s: %v\n", key, err)
+	}
+}
+
+// Вспомогательный метод составляющий ключ кэша графика из символа и всех параметров запроса
+func plotKey(symbol string, options plot.PlotOptions) string {
+	return fmt.Sprintf("plot|%s|%s|%s|%s|%v|%s", symbol, options.Interval,
+		options.From.Format(time.RFC3339), options.To.Format(time.RFC3339), options.Adjusted, options.AssetClass)
+}
@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"InvestmentHelpver_V2/internal/news"
+	"InvestmentHelpver_V2/internal/plot"
+
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Заглушка PlotManager и NewsManager считающая обращения
+type testManager struct {
+	calls int32
+	delay time.Duration
+	err   error
+}
+
+func (manager *testManager) GetPlot(symbol string, options plot.PlotOptions) ([]plot.Candle, error) {
+	atomic.AddInt32(&manager.calls, 1)
+	time.Sleep(manager.delay)
+	if manager.err != nil {
+		return nil, manager.err
+	}
+	return []plot.Candle{{Date: time.Date(2020, 5, 12, 0, 0, 0, 0, time.UTC), Close: 1}}, nil
+}
+
+func (manager *testManager) GetNews(symbol string) ([]news.News, error) {
+	atomic.AddInt32(&manager.calls, 1)
+	if manager.err != nil {
+		return nil, manager.err
+	}
+	return []news.News{{Headline: "headline", Link: "https://example.com"}}, nil
+}
+
+func TestCacheManager(t *testing.T) {
+	t.Run("test plot cached", func(t *testing.T) {
+		source := &testManager{}
+		cacheManager := NewCacheManager(source, nil, NewBackendLRU(10), TTL{Intraday: time.Minute})
+		for i := 0; i < 3; i++ {
+			plotSlice, err := cacheManager.GetPlot("IBM", plot.PlotOptions{Interval: plot.Interval5Min})
+			if err != nil || len(plotSlice) != 1 {
+				t.Fatalf("wrong result %v %v", plotSlice, err)
+			}
+		}
+		cacheManager.GetPlot("IBM", plot.PlotOptions{Interval: plot.Interval60Min})
+		if source.calls != 2 {
+			t.Errorf("wrong source calls, want 2, get %d", source.calls)
+		}
+	})
+
+	t.Run("test news cached until ttl", func(t *testing.T) {
+		source := &testManager{}
+		backend := NewBackendLRU(10).(*BackendLRU)
+		now := time.Now()
+		backend.now = func() time.Time { return now }
+		cacheManager := NewCacheManager(nil, source, backend, TTL{News: 5 * time.Minute})
+		cacheManager.GetNews("IBM")
+		cacheManager.GetNews("IBM")
+		now = now.Add(5 * time.Minute)
+		newsSlice, err := cacheManager.GetNews("IBM")
+		if err != nil || len(newsSlice) != 1 || newsSlice[0].Headline != "headline" {
+			t.Fatalf("wrong result %v %v", newsSlice, err)
+		}
+		if source.calls != 2 {
+			t.Errorf("wrong source calls, want 2, get %d", source.calls)
+		}
+	})
+
+	t.Run("test errors not cached", func(t *testing.T) {
+		source := &testManager{err: errors.New("exceedApiFrequency")}
+		cacheManager := NewCacheManager(source, source, NewBackendLRU(10), TTL{News: time.Minute})
+		for i := 0; i < 2; i++ {
+			if _, err := cacheManager.GetNews("IBM"); err == nil {
+				t.Error("expected error")
+			}
+		}
+		if source.calls != 2 {
+			t.Errorf("wrong source calls, want 2, get %d", source.calls)
+		}
+	})
+
+	t.Run("test concurrent requests deduplicated", func(t *testing.T) {
+		source := &testManager{delay: 50 * time.Millisecond}
+		cacheManager := NewCacheManager(source, nil, NewBackendLRU(10), TTL{})
+		var wait sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wait.Add(1)
+			go func() {
+				defer wait.Done()
+				if _, err := cacheManager.GetPlot("IBM", plot.PlotOptions{}); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wait.Wait()
+		if source.calls != 1 {
+			t.Errorf("wrong source calls, want 1, get %d", source.calls)
+		}
+	})
+
+	t.Run("test source preserved", func(t *testing.T) {
+		fallback := plot.NewPlotManagerFallback(plot.Provider{Name: "stub", Manager: &testManager{}})
+		cacheManager := NewCacheManager(fallback, nil, NewBackendLRU(10), TTL{})
+		for i := 0; i < 2; i++ {
+			_, source, err := cacheManager.GetPlotWithSource("IBM", plot.PlotOptions{})
+			if err != nil || source != "stub" {
+				t.Errorf("wrong source %s, error %v", source, err)
+			}
+		}
+	})
+}
+
+func TestPlotTTL(t *testing.T) {
+	cacheManager := NewCacheManager(nil, nil, NewBackendLRU(1), TTL{Intraday: time.Minute})
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	cacheManager.now = func() time.Time { return time.Date(2020, 5, 12, 15, 0, 0, 0, newYork) }
+	if ttl := cacheManager.plotTTL("IBM", plot.PlotOptions{Interval: plot.IntervalDaily}); ttl != time.Hour {
+		t.Errorf("wrong daily ttl, want 1h, get %s", ttl)
+	}
+	if ttl := cacheManager.plotTTL("IBM", plot.PlotOptions{Interval: plot.Interval1Min}); ttl != time.Minute {
+		t.Errorf("wrong intraday ttl, want 1m, get %s", ttl)
+	}
+
+	// пятница 20:00 по Нью-Йорку (суббота 00:00 UTC): акции ждут закрытия понедельника, криптовалюта - полуночи воскресенья по UTC,
+	// валютная пара - полуночи понедельника по UTC
+	cacheManager.now = func() time.Time { return time.Date(2020, 5, 15, 20, 0, 0, 0, newYork) }
+	tests := []struct {
+		symbol     string
+		assetClass plot.AssetClass
+		want       time.Duration
+	}{
+		{"IBM", "", 68 * time.Hour},
+		{"BTC", plot.AssetClassCrypto, 24 * time.Hour},
+		{"BTC/USD", "", 24 * time.Hour},
+		{"EUR/USD", "", 48 * time.Hour},
+		{"EUR/USD", plot.AssetClassFX, 48 * time.Hour},
+	}
+	for _, test := range tests {
+		options := plot.PlotOptions{Interval: plot.IntervalDaily, AssetClass: test.assetClass}
+		if ttl := cacheManager.plotTTL(test.symbol, options); ttl != test.want {
+			t.Errorf("wrong daily ttl for %s %q, want %s, get %s", test.symbol, test.assetClass, test.want, ttl)
+		}
+	}
+}
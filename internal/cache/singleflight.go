@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"sync"
+)
+
+// Структура group объединяет одновременные одинаковые запросы: пока выполняется запрос по ключу,
+// остальные запросы с тем же ключом ждут его результата вместо повторного обращения к источнику
+type group struct {
+	mutex sync.Mutex
+	calls map[string]*call
+}
+
+// Выполняющийся запрос
+type call struct {
+	done  sync.WaitGroup
+	value interface{}
+	err   error
+}
+
+// Метод выполняющий fn для ключа один раз для всех одновременных вызовов, возвращает общий результат
+func (g *group) Do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mutex.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*call{}
+	}
+	if current, ok := g.calls[key]; ok {
+		g.mutex.Unlock()
+		current.done.Wait()
+		return current.value, current.err
+	}
+	current := &call{}
+	current.done.Add(1)
+	g.calls[key] = current
+	g.mutex.Unlock()
+
+	defer func() {
+		g.mutex.Lock()
+		delete(g.calls, key)
+		g.mutex.Unlock()
+		current.done.Done()
+	}()
+	current.value, current.err = fn()
+	return current.value, current.err
+}
@@ -0,0 +1,334 @@
+package calendar
+
+import (
+	"InvestmentHelpver_V2/internal/alphavantage"
+	"InvestmentHelpver_V2/internal/plot"
+
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Вид событий календаря: earnings - отчеты о прибыли, dividends - дивиденды
+type Kind string
+
+const (
+	KindEarnings  Kind = "earnings"
+	KindDividends Kind = "dividends"
+)
+
+// Метод превращающий строку из запроса в Kind, пустая строка означает все виды событий и возвращается как есть
+func ParseKind(s string) (Kind, error) {
+	kind := Kind(strings.ToLower(s))
+	switch kind {
+	case "", KindEarnings, KindDividends:
+		return kind, nil
+	}
+	return "", errors.New("wrongKind")
+}
+
+// Структура Event содержит событие календаря. Date - дата события (дата отчета или дата отсечки дивиденда),
+// CandleDate - дата дневной свечи на которой событие отражается в цене в том же формате что Candle.Date:
+// отчет после закрытия торгов отражается в следующей торговой сессии, событие в выходной - в ближайшей торговой сессии.
+// Поля отчетов заполняются только для earnings, поля дивидендов только для dividends, еще не известные значения отсутствуют
+type Event struct {
+	Symbol           string
+	Kind             Kind
+	Date             time.Time
+	CandleDate       time.Time
+	Upcoming         bool       // событие еще не наступило
+	FiscalDateEnding *time.Time `json:",omitempty"` // конец отчетного квартала
+	ReportTime       string     `json:",omitempty"` // pre-market или post-market
+	EstimatedEPS     *float64   `json:",omitempty"` // ожидаемая прибыль на акцию
+	ReportedEPS      *float64   `json:",omitempty"` // фактическая прибыль на акцию
+	Surprise         *float64   `json:",omitempty"` // разница фактической и ожидаемой прибыли на акцию
+	SurprisePercent  *float64   `json:",omitempty"` // разница фактической и ожидаемой прибыли на акцию в процентах от ожидаемой
+	Amount           *float64   `json:",omitempty"` // размер дивиденда на акцию
+	DeclarationDate  *time.Time `json:",omitempty"` // дата объявления дивиденда
+	RecordDate       *time.Time `json:",omitempty"` // дата закрытия реестра
+	PaymentDate      *time.Time `json:",omitempty"` // дата выплаты
+}
+
+// интерфейс менеджера календаря событий, реализующие его струтуры должны иметь метод получающий символ финансового актива и вид событий
+// и возвращать прошедшие и предстоящие события в порядке возрастания даты
+type CalendarManager interface {
+	GetEvents(string, Kind) ([]Event, error) // принимает символ и вид событий (пустой - все виды), возвращает список экземпляров структуры Event
+}
+
+// Реализация интерфейса CalendarManager, получает прошедшие отчеты функцией EARNINGS, предстоящие функцией EARNINGS_CALENDAR
+// и дивиденды функцией DIVIDENDS Alpha Vantage
+type CalendarManagerAlphaVantage struct {
+	Client *alphavantage.Client
+	now    func() time.Time
+}
+
+// Конструктор для структуры CalendarManagerAlphaVantage, принимает настроенный клиент Alpha Vantage
+func NewCalendarManagerAlphaVantage(client *alphavantage.Client) CalendarManager {
+	calendarManager := CalendarManagerAlphaVantage{client, time.Now}
+	return calendarManager
+}
+
+// Метод структуры CalendarManagerAlphaVantage, принимает символ финансового актива и вид событий, возвращает список экземпляров структуры Event
+func (calendarManager CalendarManagerAlphaVantage) GetEvents(symbol string, kind Kind) ([]Event, error) {
+	events := []Event{}
+	if kind == "" || kind == KindEarnings {
+		earnings, err := calendarManager.getEarnings(symbol)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, earnings...)
+	}
+	if kind == "" || kind == KindDividends {
+		body, err := calendarManager.Client.Query(url.Values{"function": {"DIVIDENDS"}, "symbol": {symbol}})
+		if err != nil {
+			return nil, err
+		}
+		dividends, err := ScrapDividendsBody(body)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, dividends...)
+	}
+	today := calendarManager.now().UTC().Truncate(24 * time.Hour)
+	for i := range events {
+		events[i].Upcoming = !events[i].Date.Before(today) && events[i].ReportedEPS == nil
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Date.Before(events[j].Date) })
+	return events, nil
+}
+
+// Вспомогательный метод получающий прошедшие и предстоящие отчеты, предстоящие отчеты по кварталам у которых уже есть
+// фактический отчет пропускаются
+func (calendarManager CalendarManagerAlphaVantage) getEarnings(symbol string) ([]Event, error) {
+	body, err := calendarManager.Client.Query(url.Values{"function": {"EARNINGS"}, "symbol": {symbol}})
+	if err != nil {
+		return nil, err
+	}
+	reported, err := ScrapEarningsBody(body)
+	if err != nil {
+		return nil, err
+	}
+	body, err = calendarManager.Client.QueryCSV(url.Values{"function": {"EARNINGS_CALENDAR"}, "symbol": {symbol}, "horizon": {"3month"}})
+	if err != nil {
+		return nil, err
+	}
+	upcoming, err := ScrapEarningsCalendar(body)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[time.Time]bool{}
+	for _, event := range reported {
+		if event.FiscalDateEnding != nil {
+			seen[*event.FiscalDateEnding] = true
+		}
+	}
+	for _, event := range upcoming {
+		if event.FiscalDateEnding == nil || !seen[*event.FiscalDateEnding] {
+			reported = append(reported, event)
+		}
+	}
+	return reported, nil
+}
+
+// Структура earningsResponse - ответ функции EARNINGS Alpha Vantage
+type earningsResponse struct {
+	Symbol            string `json:"symbol"`
+	QuarterlyEarnings []struct {
+		FiscalDateEnding   string `json:"fiscalDateEnding"`
+		ReportedDate       string `json:"reportedDate"`
+		ReportedEPS        string `json:"reportedEPS"`
+		EstimatedEPS       string `json:"estimatedEPS"`
+		Surprise           string `json:"surprise"`
+		SurprisePercentage string `json:"surprisePercentage"`
+		ReportTime         string `json:"reportTime"`
+	} `json:"quarterlyEarnings"`
+}
+
+// Метод превращающий тело ответа EARNINGS в список событий earnings,
+// для неизвестного символа Alpha Vantage отдает пустой объект, в этом случае возвращается alphavantage.ErrUnknownSymbol
+func ScrapEarningsBody(body []byte) ([]Event, error) {
+	var response earningsResponse
+	err := json.Unmarshal(body, &response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+	}
+	if response.Symbol == "" {
+		return nil, fmt.Errorf("%w: empty earnings", alphavantage.ErrUnknownSymbol)
+	}
+	events := make([]Event, 0, len(response.QuarterlyEarnings))
+	for _, values := range response.QuarterlyEarnings {
+		event := Event{Symbol: response.Symbol, Kind: KindEarnings, ReportTime: values.ReportTime}
+		event.Date, err = time.Parse("2006-01-02", values.ReportedDate)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+		}
+		event.FiscalDateEnding, err = parseDate(values.FiscalDateEnding)
+		if err != nil {
+			return nil, err
+		}
+		fields := []struct {
+			value string
+			field **float64
+		}{
+			{values.ReportedEPS, &event.ReportedEPS},
+			{values.EstimatedEPS, &event.EstimatedEPS},
+			{values.Surprise, &event.Surprise},
+			{values.SurprisePercentage, &event.SurprisePercent},
+		}
+		for _, field := range fields {
+			*field.field, err = parseValue(field.value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		event.CandleDate = candleDate(event.Date, event.ReportTime)
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Метод превращающий CSV ответ EARNINGS_CALENDAR (symbol,name,reportDate,fiscalDateEnding,estimate,currency)
+// в список предстоящих событий earnings, для неизвестного символа ответ содержит только заголовок
+func ScrapEarningsCalendar(body []byte) ([]Event, error) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []Event{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"symbol", "reportDate", "fiscalDateEnding", "estimate"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %s", alphavantage.ErrMalformedResponse, name)
+		}
+	}
+	events := []Event{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+		}
+		event := Event{Symbol: record[columns["symbol"]], Kind: KindEarnings}
+		event.Date, err = time.Parse("2006-01-02", record[columns["reportDate"]])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+		}
+		event.FiscalDateEnding, err = parseDate(record[columns["fiscalDateEnding"]])
+		if err != nil {
+			return nil, err
+		}
+		event.EstimatedEPS, err = parseValue(record[columns["estimate"]])
+		if err != nil {
+			return nil, err
+		}
+		event.CandleDate = candleDate(event.Date, "")
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Структура dividendsResponse - ответ функции DIVIDENDS Alpha Vantage
+type dividendsResponse struct {
+	Symbol string `json:"symbol"`
+	Data   []struct {
+		ExDividendDate  string `json:"ex_dividend_date"`
+		DeclarationDate string `json:"declaration_date"`
+		RecordDate      string `json:"record_date"`
+		PaymentDate     string `json:"payment_date"`
+		Amount          string `json:"amount"`
+	} `json:"data"`
+}
+
+// Метод превращающий тело ответа DIVIDENDS в список событий dividends, датой события считается дата отсечки (ex-dividend),
+// для неизвестного символа Alpha Vantage отдает пустой объект, в этом случае возвращается alphavantage.ErrUnknownSymbol
+func ScrapDividendsBody(body []byte) ([]Event, error) {
+	var response dividendsResponse
+	err := json.Unmarshal(body, &response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+	}
+	if response.Symbol == "" {
+		return nil, fmt.Errorf("%w: empty dividends", alphavantage.ErrUnknownSymbol)
+	}
+	events := make([]Event, 0, len(response.Data))
+	for _, values := range response.Data {
+		event := Event{Symbol: response.Symbol, Kind: KindDividends}
+		event.Date, err = time.Parse("2006-01-02", values.ExDividendDate)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+		}
+		dates := []struct {
+			value string
+			field **time.Time
+		}{
+			{values.DeclarationDate, &event.DeclarationDate},
+			{values.RecordDate, &event.RecordDate},
+			{values.PaymentDate, &event.PaymentDate},
+		}
+		for _, field := range dates {
+			*field.field, err = parseDate(field.value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		event.Amount, err = parseValue(values.Amount)
+		if err != nil {
+			return nil, err
+		}
+		event.CandleDate = candleDate(event.Date, "")
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Вспомогательный метод возвращающий дату дневной свечи на которой событие отражается в цене:
+// отчет после закрытия торгов переносится на следующий день, затем дата сдвигается на ближайший торговый день NYSE
+func candleDate(date time.Time, reportTime string) time.Time {
+	if reportTime == "post-market" {
+		date = date.AddDate(0, 0, 1)
+	}
+	for !plot.IsTradingDay(date) {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date
+}
+
+// Вспомогательный метод превращающий дату в формате yyyy-mm-dd в *time.Time, возвращает nil если дата не известна (None, пустая строка)
+func parseDate(s string) (*time.Time, error) {
+	if s == "" || s == "None" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+	}
+	return &date, nil
+}
+
+// Вспомогательный метод превращающий значение в *float64, возвращает nil если значение не известно (None, пустая строка)
+func parseValue(s string) (*float64, error) {
+	if s == "" || s == "None" {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+	}
+	return &value, nil
+}
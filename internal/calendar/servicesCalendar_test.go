@@ -0,0 +1,142 @@
+package calendar
+
+import (
+	"InvestmentHelpver_V2/internal/alphavantage"
+
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var testEarningsBody = `{"symbol": "IBM", "annualEarnings": [], "quarterlyEarnings": [
+	{"fiscalDateEnding": "2023-12-31", "reportedDate": "2024-01-24", "reportedEPS": "3.87", "estimatedEPS": "3.78",
+	"surprise": "0.09", "surprisePercentage": "2.381", "reportTime": "post-market"},
+	{"fiscalDateEnding": "2023-09-30", "reportedDate": "2023-10-25", "reportedEPS": "2.2", "estimatedEPS": "None",
+	"surprise": "0", "surprisePercentage": "None", "reportTime": "pre-market"}]}`
+
+var testEarningsCalendar = "symbol,name,reportDate,fiscalDateEnding,estimate,currency\n" +
+	"IBM,International Business Machines,2024-01-24,2023-12-31,3.78,USD\n" +
+	"IBM,International Business Machines,2099-04-18,2099-03-31,,USD\n"
+
+var testDividendsBody = `{"symbol": "IBM", "data": [
+	{"ex_dividend_date": "2099-02-07", "declaration_date": "2099-01-30", "record_date": "None", "payment_date": "None", "amount": "1.67"},
+	{"ex_dividend_date": "2023-11-09", "declaration_date": "2023-10-31", "record_date": "2023-11-10", "payment_date": "2023-12-09", "amount": "1.66"}]}`
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseKind(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Kind
+		wantErr bool
+	}{
+		{"", "", false},
+		{"Earnings", KindEarnings, false},
+		{"dividends", KindDividends, false},
+		{"splits", "", true},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test kind:%q", test.input), func(t *testing.T) {
+			kind, err := ParseKind(test.input)
+			if (err != nil) != test.wantErr || kind != test.want {
+				t.Errorf("wrong result %s, error %v", kind, err)
+			}
+		})
+	}
+}
+
+func TestScrapEarningsBody(t *testing.T) {
+	events, err := ScrapEarningsBody([]byte(testEarningsBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("wrong events count, want 2, get %d", len(events))
+	}
+	// отчет 24.01.2024 (среда) после закрытия отражается в свече 25.01.2024
+	if !events[0].Date.Equal(date(2024, time.January, 24)) || !events[0].CandleDate.Equal(date(2024, time.January, 25)) ||
+		*events[0].ReportedEPS != 3.87 || *events[0].SurprisePercent != 2.381 {
+		t.Errorf("wrong event %+v", events[0])
+	}
+	if !events[1].CandleDate.Equal(date(2023, time.October, 25)) || events[1].EstimatedEPS != nil || events[1].SurprisePercent != nil {
+		t.Errorf("wrong event %+v", events[1])
+	}
+	if _, err := ScrapEarningsBody([]byte(`{}`)); !errors.Is(err, alphavantage.ErrUnknownSymbol) {
+		t.Errorf("wrong error %v", err)
+	}
+}
+
+func TestScrapDividendsBody(t *testing.T) {
+	events, err := ScrapDividendsBody([]byte(testDividendsBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || *events[1].Amount != 1.66 || !events[1].PaymentDate.Equal(date(2023, time.December, 9)) {
+		t.Fatalf("wrong events %+v", events)
+	}
+	if events[0].RecordDate != nil || events[0].PaymentDate != nil {
+		t.Errorf("unknown dates must be nil %+v", events[0])
+	}
+}
+
+func TestCandleDate(t *testing.T) {
+	tests := []struct {
+		date       time.Time
+		reportTime string
+		want       time.Time
+	}{
+		{date(2024, time.January, 24), "pre-market", date(2024, time.January, 24)},
+		{date(2024, time.January, 26), "post-market", date(2024, time.January, 29)}, // пятница -> понедельник
+		{date(2023, time.December, 24), "", date(2023, time.December, 26)},          // воскресенье и Рождество
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test date:%s %s", test.date.Format("2006-01-02"), test.reportTime), func(t *testing.T) {
+			if got := candleDate(test.date, test.reportTime); !got.Equal(test.want) {
+				t.Errorf("wrong candle date, want %s, get %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestCalendarManagerAlphaVantage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("function") {
+		case "EARNINGS":
+			fmt.Fprint(w, testEarningsBody)
+		case "EARNINGS_CALENDAR":
+			fmt.Fprint(w, testEarningsCalendar)
+		case "DIVIDENDS":
+			fmt.Fprint(w, testDividendsBody)
+		}
+	}))
+	defer server.Close()
+	client := alphavantage.NewClient(server.URL, server.Client(), alphavantage.NewKeyPool("TESTKEY"), nil)
+	calendarManager := CalendarManagerAlphaVantage{client, func() time.Time { return date(2024, time.June, 1) }}
+
+	events, err := calendarManager.GetEvents("IBM", KindEarnings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// предстоящий отчет за уже отчитавшийся квартал пропускается
+	if len(events) != 3 || !events[0].Date.Equal(date(2023, time.October, 25)) || !events[2].Upcoming || events[1].Upcoming {
+		t.Errorf("wrong earnings %+v", events)
+	}
+
+	events, err = calendarManager.GetEvents("IBM", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 5 {
+		t.Fatalf("wrong events count, want 5, get %d", len(events))
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Date.Before(events[i-1].Date) {
+			t.Errorf("events are not sorted %+v", events)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Типы операций портфеля
+const (
+	TransactionBuy  = "buy"
+	TransactionSell = "sell"
+)
+
+// Структура Transaction содержит операцию покупки или продажи финансового актива в портфеле пользователя
+type Transaction struct {
+	UserID   string    `bson:"userID"`   // индентификатор пользователя в системе
+	Symbol   string    `bson:"symbol"`   // символ финансового актива
+	Type     string    `bson:"type"`     // buy или sell
+	Date     time.Time `bson:"date"`     // дата операции
+	Quantity float64   `bson:"quantity"` // количество, всегда положительное
+	Price    float64   `bson:"price"`    // цена за единицу без комиссии
+	Fees     float64   `bson:"fees"`     // комиссия за всю операцию
+}
+
+// интерфейс менеджера портфелей, реализующие его струтуры должны иметь метод GetTransactions принимающий ID пользователя
+// и возвращающий его операции в порядке дат и метод AddTransaction записывающий операцию в базу данных
+type PortfolioManager interface {
+	GetTransactions(string) ([]Transaction, error) // принимает ID пользователя, возвращает его операции отсортированные по дате
+	AddTransaction(Transaction) error              // записывает операцию в базу данных
+}
+
+// Реализация интерфейса PortfolioManager, хранит операции пользователей в коллекции MongoDB
+type PortfolioManagerMongo struct {
+	DBCollection *mongo.Collection //коллекция mongodb в которую записываются данные
+}
+
+// Конструктор для структуры PortfolioManagerMongo, создает индекс по пользователю и дате операции
+func NewPortfolioManagerMongo(dbName, collectionName, dbServer string) PortfolioManager {
+	collection, _, err := GetCollection(dbName, collectionName, dbServer)
+	if err != nil {
+		return nil
+	}
+	index := mongo.IndexModel{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "date", Value: 1}}}
+	_, err = collection.Indexes().CreateOne(context.TODO(), index)
+	if err != nil {
+		return nil
+	}
+	return PortfolioManagerMongo{collection}
+}
+
+// Метод структуры PortfolioManagerMongo, принимает ID пользователя, возвращает его операции отсортированные по дате,
+// операции с одной датой возвращаются в порядке записи
+func (portfolioManager PortfolioManagerMongo) GetTransactions(userID string) ([]Transaction, error) {
+	transactions := []Transaction{}
+	findOptions := options.Find().SetSort(bson.D{{Key: "date", Value: 1}, {Key: "_id", Value: 1}})
+	cur, err := portfolioManager.DBCollection.Find(context.TODO(), bson.M{"userID": userID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(context.TODO())
+	for cur.Next(context.TODO()) {
+		var transaction Transaction
+		err := cur.Decode(&transaction)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, cur.Err()
+}
+
+// Метод структуры PortfolioManagerMongo, записывает операцию в базу данных, возвращает ошибку если она есть
+func (portfolioManager PortfolioManagerMongo) AddTransaction(transaction Transaction) error {
+	_, err := portfolioManager.DBCollection.InsertOne(context.TODO(), transaction)
+	return err
+}
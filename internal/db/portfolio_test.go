@@ -0,0 +1,39 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPortfolioMongo(t *testing.T) {
+	testUser := "TestUser"
+	dbName, collectionNameTest, mongoServer := "InvestmentHelper", "PortfolioTest", "mongodb://127.0.0.1:27017"
+	portfolioManagerTest := NewPortfolioManagerMongo(dbName, collectionNameTest, mongoServer)
+	if portfolioManagerTest == nil {
+		t.Skip("mongodb is not available")
+	}
+	defer deleteMongoCollection(dbName, collectionNameTest, mongoServer)
+	transactions := []Transaction{
+		{testUser, "IBM", TransactionSell, time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC), 5, 120, 1},
+		{testUser, "IBM", TransactionBuy, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), 10, 100, 1},
+		{"OtherUser", "AAPL", TransactionBuy, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), 1, 300, 0},
+	}
+	t.Run("test add transactions", func(t *testing.T) {
+		for _, transaction := range transactions {
+			err := portfolioManagerTest.AddTransaction(transaction)
+			if err != nil {
+				t.Error(err)
+			}
+		}
+	})
+
+	t.Run("test read transactions", func(t *testing.T) {
+		read, err := portfolioManagerTest.GetTransactions(testUser)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(read) != 2 || read[0].Type != TransactionBuy || read[1].Type != TransactionSell {
+			t.Errorf("wrong transactions %+v", read)
+		}
+	})
+}
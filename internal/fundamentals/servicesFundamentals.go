@@ -0,0 +1,271 @@
+package fundamentals
+
+import (
+	"InvestmentHelpver_V2/internal/alphavantage"
+
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Структура Overview содержит основные сведения о компании и ее оценку рынком
+type Overview struct {
+	Symbol            string  // символ финансового актива
+	Name              string  // название компании
+	Exchange          string  // биржа
+	Currency          string  // валюта отчетности и торгов
+	Sector            string  // сектор экономики
+	Industry          string  // отрасль
+	MarketCap         int64   // рыночная капитализация
+	PERatio           float64 // цена / прибыль на акцию
+	EPS               float64 // прибыль на акцию за последние 12 месяцев
+	DividendYield     float64 // дивидендная доходность (0.05 - 5%)
+	Week52High        float64 // наивысшая цена за 52 недели
+	Week52Low         float64 // наименьшая цена за 52 недели
+	Beta              float64 // бета относительно рынка
+	SharesOutstanding int64   // количество акций в обращении
+}
+
+// Вид финансовой отчетности: overview, income, balance, cashflow
+type Statement string
+
+const (
+	StatementOverview Statement = "overview"
+	StatementIncome   Statement = "income"
+	StatementBalance  Statement = "balance"
+	StatementCashFlow Statement = "cashflow"
+)
+
+// Метод превращающий строку из запроса в Statement, пустая строка означает overview
+func ParseStatement(s string) (Statement, error) {
+	if s == "" {
+		return StatementOverview, nil
+	}
+	statement := Statement(s)
+	switch statement {
+	case StatementOverview, StatementIncome, StatementBalance, StatementCashFlow:
+		return statement, nil
+	}
+	return "", errors.New("wrongStatement")
+}
+
+// Вспомогательный метод возвращающий название функции Alpha Vantage для отчетности
+func (statement Statement) function() string {
+	switch statement {
+	case StatementIncome:
+		return "INCOME_STATEMENT"
+	case StatementBalance:
+		return "BALANCE_SHEET"
+	case StatementCashFlow:
+		return "CASH_FLOW"
+	}
+	return "OVERVIEW"
+}
+
+// Статьи отчетности попадающие в сводку, названия совпадают с полями Alpha Vantage
+var summaryItems = map[Statement][]string{
+	StatementIncome: {"totalRevenue", "grossProfit", "operatingIncome", "ebitda", "netIncome"},
+	StatementBalance: {"totalAssets", "totalLiabilities", "totalShareholderEquity", "cashAndCashEquivalentsAtCarryingValue",
+		"longTermDebt", "commonStockSharesOutstanding"},
+	StatementCashFlow: {"operatingCashflow", "capitalExpenditures", "dividendPayout", "netIncome"},
+}
+
+// Структура Report содержит сводку одного отчета: дату окончания периода и значения основных статей,
+// статьи которые компания не раскрыла в сводку не попадают. В сводке движения денежных средств
+// дополнительно рассчитывается freeCashFlow = operatingCashflow - capitalExpenditures
+type Report struct {
+	FiscalDateEnding time.Time
+	ReportedCurrency string
+	Items            map[string]float64
+}
+
+// Структура Statements содержит сводки годовых и квартальных отчетов компании, от новых к старым
+type Statements struct {
+	Symbol    string
+	Statement Statement
+	Annual    []Report
+	Quarterly []Report
+}
+
+// интерфейс менеджера фундаментальных данных, реализующие его струтуры должны иметь методы получающие символ финансового актива
+// и возвращающие основные сведения о компании и сводки ее финансовой отчетности
+type FundamentalsManager interface {
+	GetOverview(string) (Overview, error)                // принимает символ финансового актива, возвращает основные сведения о компании
+	GetStatements(string, Statement) (Statements, error) // принимает символ и вид отчетности (income, balance, cashflow), возвращает сводки отчетов
+}
+
+// Реализация интерфейса FundamentalsManager, получает данные функциями OVERVIEW, INCOME_STATEMENT, BALANCE_SHEET и CASH_FLOW Alpha Vantage
+type FundamentalsManagerAlphaVantage struct {
+	Client *alphavantage.Client
+}
+
+// Конструктор для структуры FundamentalsManagerAlphaVantage, принимает настроенный клиент Alpha Vantage
+func NewFundamentalsManagerAlphaVantage(client *alphavantage.Client) FundamentalsManager {
+	fundamentalsManager := FundamentalsManagerAlphaVantage{client}
+	return fundamentalsManager
+}
+
+// Метод структуры FundamentalsManagerAlphaVantage, принимает символ финансового актива, возвращает основные сведения о компании
+func (fundamentalsManager FundamentalsManagerAlphaVantage) GetOverview(symbol string) (Overview, error) {
+	body, err := fundamentalsManager.Client.Query(url.Values{"function": {StatementOverview.function()}, "symbol": {symbol}})
+	if err != nil {
+		return Overview{}, err
+	}
+	return ScrapOverviewBody(body)
+}
+
+// Метод структуры FundamentalsManagerAlphaVantage, принимает символ финансового актива и вид отчетности, возвращает сводки отчетов
+func (fundamentalsManager FundamentalsManagerAlphaVantage) GetStatements(symbol string, statement Statement) (Statements, error) {
+	if _, ok := summaryItems[statement]; !ok {
+		return Statements{}, errors.New("wrongStatement")
+	}
+	body, err := fundamentalsManager.Client.Query(url.Values{"function": {statement.function()}, "symbol": {symbol}})
+	if err != nil {
+		return Statements{}, err
+	}
+	return ScrapStatementsBody(body, statement)
+}
+
+// Структура overviewResponse - ответ функции OVERVIEW Alpha Vantage
+type overviewResponse struct {
+	Symbol               string `json:"Symbol"`
+	Name                 string `json:"Name"`
+	Exchange             string `json:"Exchange"`
+	Currency             string `json:"Currency"`
+	Sector               string `json:"Sector"`
+	Industry             string `json:"Industry"`
+	MarketCapitalization string `json:"MarketCapitalization"`
+	PERatio              string `json:"PERatio"`
+	EPS                  string `json:"EPS"`
+	DividendYield        string `json:"DividendYield"`
+	Week52High           string `json:"52WeekHigh"`
+	Week52Low            string `json:"52WeekLow"`
+	Beta                 string `json:"Beta"`
+	SharesOutstanding    string `json:"SharesOutstanding"`
+}
+
+// Метод превращающий тело ответа OVERVIEW в экземпляр структуры Overview, нераскрытые показатели (None, -) равны 0,
+// для неизвестного символа Alpha Vantage отдает пустой объект, в этом случае возвращается alphavantage.ErrUnknownSymbol
+func ScrapOverviewBody(body []byte) (Overview, error) {
+	var response overviewResponse
+	err := json.Unmarshal(body, &response)
+	if err != nil {
+		return Overview{}, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+	}
+	if response.Symbol == "" {
+		return Overview{}, fmt.Errorf("%w: empty overview", alphavantage.ErrUnknownSymbol)
+	}
+	overview := Overview{
+		Symbol:   response.Symbol,
+		Name:     response.Name,
+		Exchange: response.Exchange,
+		Currency: response.Currency,
+		Sector:   response.Sector,
+		Industry: response.Industry,
+	}
+	floats := []struct {
+		value string
+		field *float64
+	}{
+		{response.PERatio, &overview.PERatio},
+		{response.EPS, &overview.EPS},
+		{response.DividendYield, &overview.DividendYield},
+		{response.Week52High, &overview.Week52High},
+		{response.Week52Low, &overview.Week52Low},
+		{response.Beta, &overview.Beta},
+	}
+	for _, field := range floats {
+		value, ok, err := parseValue(field.value)
+		if err != nil {
+			return Overview{}, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+		}
+		if ok {
+			*field.field = value
+		}
+	}
+	marketCap, _, err := parseValue(response.MarketCapitalization)
+	if err != nil {
+		return Overview{}, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+	}
+	sharesOutstanding, _, err := parseValue(response.SharesOutstanding)
+	if err != nil {
+		return Overview{}, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+	}
+	overview.MarketCap = int64(marketCap)
+	overview.SharesOutstanding = int64(sharesOutstanding)
+	return overview, nil
+}
+
+// Структура statementsResponse - ответ функций INCOME_STATEMENT, BALANCE_SHEET и CASH_FLOW Alpha Vantage
+type statementsResponse struct {
+	Symbol           string              `json:"symbol"`
+	AnnualReports    []map[string]string `json:"annualReports"`
+	QuarterlyReports []map[string]string `json:"quarterlyReports"`
+}
+
+// Метод превращающий тело ответа INCOME_STATEMENT, BALANCE_SHEET или CASH_FLOW в экземпляр структуры Statements,
+// для неизвестного символа Alpha Vantage отдает пустой объект, в этом случае возвращается alphavantage.ErrUnknownSymbol
+func ScrapStatementsBody(body []byte, statement Statement) (Statements, error) {
+	var response statementsResponse
+	err := json.Unmarshal(body, &response)
+	if err != nil {
+		return Statements{}, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+	}
+	if response.Symbol == "" {
+		return Statements{}, fmt.Errorf("%w: empty statements", alphavantage.ErrUnknownSymbol)
+	}
+	statements := Statements{Symbol: response.Symbol, Statement: statement}
+	statements.Annual, err = summarize(response.AnnualReports, statement)
+	if err != nil {
+		return Statements{}, err
+	}
+	statements.Quarterly, err = summarize(response.QuarterlyReports, statement)
+	if err != nil {
+		return Statements{}, err
+	}
+	return statements, nil
+}
+
+// Вспомогательный метод превращающий отчеты из ответа Alpha Vantage в сводки с основными статьями
+func summarize(reports []map[string]string, statement Statement) ([]Report, error) {
+	summaries := make([]Report, 0, len(reports))
+	for _, values := range reports {
+		fiscalDateEnding, err := time.Parse("2006-01-02", values["fiscalDateEnding"])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+		}
+		report := Report{fiscalDateEnding, values["reportedCurrency"], map[string]float64{}}
+		for _, item := range summaryItems[statement] {
+			value, ok, err := parseValue(values[item])
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", alphavantage.ErrMalformedResponse, item, err)
+			}
+			if ok {
+				report.Items[item] = value
+			}
+		}
+		operating, okOperating := report.Items["operatingCashflow"]
+		capex, okCapex := report.Items["capitalExpenditures"]
+		if statement == StatementCashFlow && okOperating && okCapex {
+			report.Items["freeCashFlow"] = operating - capex
+		}
+		summaries = append(summaries, report)
+	}
+	return summaries, nil
+}
+
+// Вспомогательный метод превращающий значение показателя в float64, возвращает false если показатель не раскрыт (None, -, пустая строка)
+func parseValue(s string) (float64, bool, error) {
+	switch s {
+	case "", "None", "-":
+		return 0, false, nil
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return value, true, nil
+}
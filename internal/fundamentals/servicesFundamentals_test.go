@@ -0,0 +1,111 @@
+package fundamentals
+
+import (
+	"InvestmentHelpver_V2/internal/alphavantage"
+
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var testOverviewBody = `{"Symbol": "IBM", "Name": "International Business Machines", "Exchange": "NYSE", "Currency": "USD",
+	"Sector": "TECHNOLOGY", "Industry": "COMPUTER & OFFICE EQUIPMENT", "MarketCapitalization": "150000000000",
+	"PERatio": "22.5", "EPS": "7.5", "DividendYield": "0.045", "52WeekHigh": "200.1", "52WeekLow": "120.5",
+	"Beta": "None", "SharesOutstanding": "900000000"}`
+
+var testCashFlowBody = `{"symbol": "IBM", "annualReports": [
+	{"fiscalDateEnding": "2023-12-31", "reportedCurrency": "USD", "operatingCashflow": "13931000000",
+	"capitalExpenditures": "1768000000", "dividendPayout": "6040000000", "netIncome": "7502000000"},
+	{"fiscalDateEnding": "2022-12-31", "reportedCurrency": "USD", "operatingCashflow": "10435000000",
+	"capitalExpenditures": "None", "dividendPayout": "5948000000", "netIncome": "1639000000"}],
+	"quarterlyReports": []}`
+
+func TestParseStatement(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Statement
+		wantErr bool
+	}{
+		{"", StatementOverview, false},
+		{"income", StatementIncome, false},
+		{"cashflow", StatementCashFlow, false},
+		{"profit", "", true},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test statement:%q", test.input), func(t *testing.T) {
+			statement, err := ParseStatement(test.input)
+			if (err != nil) != test.wantErr || statement != test.want {
+				t.Errorf("wrong result %s, error %v", statement, err)
+			}
+		})
+	}
+}
+
+func TestScrapOverviewBody(t *testing.T) {
+	overview, err := ScrapOverviewBody([]byte(testOverviewBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if overview.Symbol != "IBM" || overview.Sector != "TECHNOLOGY" || overview.MarketCap != 150000000000 ||
+		overview.PERatio != 22.5 || overview.Week52High != 200.1 || overview.Beta != 0 || overview.SharesOutstanding != 900000000 {
+		t.Errorf("wrong overview %+v", overview)
+	}
+	if _, err := ScrapOverviewBody([]byte(`{}`)); !errors.Is(err, alphavantage.ErrUnknownSymbol) {
+		t.Errorf("wrong error %v", err)
+	}
+	if _, err := ScrapOverviewBody([]byte(`{"Symbol": "IBM", "EPS": "abc"}`)); !errors.Is(err, alphavantage.ErrMalformedResponse) {
+		t.Errorf("wrong error %v", err)
+	}
+}
+
+func TestScrapStatementsBody(t *testing.T) {
+	statements, err := ScrapStatementsBody([]byte(testCashFlowBody), StatementCashFlow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statements.Annual) != 2 || len(statements.Quarterly) != 0 {
+		t.Fatalf("wrong reports count %+v", statements)
+	}
+	latest := statements.Annual[0]
+	if !latest.FiscalDateEnding.Equal(time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)) || latest.Items["freeCashFlow"] != 12163000000 {
+		t.Errorf("wrong report %+v", latest)
+	}
+	if _, ok := statements.Annual[1].Items["freeCashFlow"]; ok {
+		t.Error("free cash flow without capital expenditures")
+	}
+	if _, err := ScrapStatementsBody([]byte(`{}`), StatementIncome); !errors.Is(err, alphavantage.ErrUnknownSymbol) {
+		t.Errorf("wrong error %v", err)
+	}
+}
+
+func TestFundamentalsManagerAlphaVantage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("function") {
+		case "OVERVIEW":
+			fmt.Fprint(w, testOverviewBody)
+		case "CASH_FLOW":
+			fmt.Fprint(w, testCashFlowBody)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	}))
+	defer server.Close()
+	client := alphavantage.NewClient(server.URL, server.Client(), alphavantage.NewKeyPool("TESTKEY"), nil)
+	fundamentalsManager := NewFundamentalsManagerAlphaVantage(client)
+
+	if overview, err := fundamentalsManager.GetOverview("IBM"); err != nil || overview.Name != "International Business Machines" {
+		t.Errorf("wrong overview %+v, error %v", overview, err)
+	}
+	if statements, err := fundamentalsManager.GetStatements("IBM", StatementCashFlow); err != nil || len(statements.Annual) != 2 {
+		t.Errorf("wrong statements %+v, error %v", statements, err)
+	}
+	if _, err := fundamentalsManager.GetStatements("IBM", StatementIncome); !errors.Is(err, alphavantage.ErrUnknownSymbol) {
+		t.Errorf("wrong error %v", err)
+	}
+	if _, err := fundamentalsManager.GetStatements("IBM", StatementOverview); err == nil {
+		t.Error("expected wrong statement error")
+	}
+}
@@ -0,0 +1,104 @@
+package fx
+
+import (
+	"InvestmentHelpver_V2/internal/plot"
+
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Структура Rate содержит курс валюты на дату: сколько единиц валюты to стоит одна единица валюты from
+type Rate struct {
+	Date time.Time
+	Rate float64
+}
+
+// интерфейс источника курсов валют, реализующие его струтуры должны иметь метод получающий пару валют и период
+// и возвращать дневные курсы за этот период в порядке возрастания даты
+type RateSource interface {
+	GetRates(string, string, time.Time, time.Time) ([]Rate, error) // принимает валюты from и to, начало и конец периода, возвращает список экземпляров структуры Rate
+}
+
+// Реализация интерфейса RateSource, получает курсы как дневные свечи валютной пары через любой PlotManager
+// (курсом дня считается цена закрытия), поэтому курсы проходят через тот же кэш и тех же поставщиков что и графики
+type RateSourcePlot struct {
+	PlotManager plot.PlotManager
+}
+
+// Конструктор для структуры RateSourcePlot
+func NewRateSourcePlot(plotManager plot.PlotManager) RateSource {
+	rateSource := RateSourcePlot{plotManager}
+	return rateSource
+}
+
+// Метод структуры RateSourcePlot, принимает валюты from и to, начало и конец периода, возвращает список экземпляров структуры Rate
+func (rateSource RateSourcePlot) GetRates(from, to string, start, end time.Time) ([]Rate, error) {
+	options := plot.PlotOptions{Interval: plot.IntervalDaily, From: start, To: end, AssetClass: plot.AssetClassFX}
+	candles, err := rateSource.PlotManager.GetPlot(from+"/"+to, options)
+	if err != nil {
+		return nil, err
+	}
+	rates := make([]Rate, len(candles))
+	for i, candle := range candles {
+		rates[i] = Rate{candle.Date, candle.Close}
+	}
+	return rates, nil
+}
+
+// Формат кода валюты ISO 4217 (или тикера криптовалюты)
+var currencyCode = regexp.MustCompile(`^[A-Z0-9]{3,5}$`)
+
+// Метод превращающий код валюты из запроса в верхний регистр и проверяющий его формат
+func ParseCurrency(s string) (string, error) {
+	currency := strings.ToUpper(strings.TrimSpace(s))
+	if !currencyCode.MatchString(currency) {
+		return "", errors.New("wrongCurrency")
+	}
+	return currency, nil
+}
+
+// Сколько дней до первой свечи запрашивается курсов, чтобы у первой свечи был курс даже после выходных и праздников
+const lookbackDays = 10
+
+// Метод пересчитывающий цены свечей из валюты from в валюту to по дневным курсам из source.
+// Каждой свече соответствует курс на ее дату, если в этот день курса нет (выходной, праздник) берется последний известный курс,
+// внутридневные свечи пересчитываются по курсу их дня. Пересчитываются цены, скорректированная цена закрытия и дивиденд,
+// объем торгов не меняется. Если для первой свечи нет ни одного курса возвращается ошибка noRate
+func Convert(candles []plot.Candle, source RateSource, from, to string) ([]plot.Candle, error) {
+	if from == to || len(candles) == 0 {
+		return candles, nil
+	}
+	start := plot.TruncateDay(candles[0].Date).AddDate(0, 0, -lookbackDays)
+	end := plot.TruncateDay(candles[len(candles)-1].Date).AddDate(0, 0, 1).Add(-time.Nanosecond)
+	rates, err := source.GetRates(from, to, start, end)
+	if err != nil {
+		return nil, err
+	}
+	converted := make([]plot.Candle, len(candles))
+	for i, candle := range candles {
+		rate, ok := rateOn(rates, plot.TruncateDay(candle.Date))
+		if !ok {
+			return nil, errors.New("noRate")
+		}
+		candle.Open *= rate
+		candle.High *= rate
+		candle.Low *= rate
+		candle.Close *= rate
+		candle.AdjustedClose *= rate
+		candle.DividendAmount *= rate
+		converted[i] = candle
+	}
+	return converted, nil
+}
+
+// Вспомогательный метод возвращающий последний курс с датой не позже day
+func rateOn(rates []Rate, day time.Time) (float64, bool) {
+	i := sort.Search(len(rates), func(i int) bool { return plot.TruncateDay(rates[i].Date).After(day) })
+	if i == 0 {
+		return 0, false
+	}
+	return rates[i-1].Rate, true
+}
@@ -0,0 +1,94 @@
+package fx
+
+import (
+	"InvestmentHelpver_V2/internal/plot"
+
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// Заглушка PlotManager возвращающая дневные свечи валютной пары USD/EUR и ошибку для остальных символов, запоминает параметры запроса
+type testPlotManager struct {
+	candles []plot.Candle
+	options *plot.PlotOptions
+}
+
+func (plotManager testPlotManager) GetPlot(symbol string, options plot.PlotOptions) ([]plot.Candle, error) {
+	if symbol != "USD/EUR" {
+		return nil, errors.New("unknownSymbol")
+	}
+	*plotManager.options = options
+	return plot.FilterCandles(plotManager.candles, options.From, options.To), nil
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseCurrency(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"eur", "EUR", false},
+		{" USD ", "USD", false},
+		{"USDT", "USDT", false},
+		{"EU", "", true},
+		{"E/R", "", true},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test currency:%q", test.input), func(t *testing.T) {
+			currency, err := ParseCurrency(test.input)
+			if (err != nil) != test.wantErr || currency != test.want {
+				t.Errorf("wrong result %s, error %v", currency, err)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	// курсы есть в пятницу 8 мая и понедельник 11 мая, в выходные берется курс пятницы
+	options := &plot.PlotOptions{}
+	source := NewRateSourcePlot(testPlotManager{[]plot.Candle{
+		{Date: date(2020, time.May, 8), Close: 0.5},
+		{Date: date(2020, time.May, 11), Close: 0.8},
+	}, options})
+	candles := []plot.Candle{
+		{Date: date(2020, time.May, 9), Open: 10, High: 12, Low: 8, Close: 11, Volume: 100},
+		{Date: date(2020, time.May, 11).Add(10 * time.Hour), Open: 10, High: 12, Low: 8, Close: 10, Volume: 100, DividendAmount: 1},
+	}
+	converted, err := Convert(candles, source, "USD", "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []plot.Candle{
+		{Date: candles[0].Date, Open: 5, High: 6, Low: 4, Close: 5.5, Volume: 100},
+		{Date: candles[1].Date, Open: 8, High: 9.6, Low: 6.4, Close: 8, Volume: 100, DividendAmount: 0.8},
+	}
+	for i := range want {
+		if math.Abs(converted[i].Close-want[i].Close) > 1e-9 || math.Abs(converted[i].High-want[i].High) > 1e-9 ||
+			math.Abs(converted[i].DividendAmount-want[i].DividendAmount) > 1e-9 || converted[i].Volume != want[i].Volume {
+			t.Errorf("wrong candle %d, want %+v, get %+v", i, want[i], converted[i])
+		}
+	}
+	if candles[0].Close != 11 {
+		t.Error("source candles must not change")
+	}
+	if options.AssetClass != plot.AssetClassFX || !options.From.Equal(date(2020, time.April, 29)) {
+		t.Errorf("wrong rate options %+v", *options)
+	}
+
+	if same, err := Convert(candles, source, "USD", "USD"); err != nil || same[0].Close != 11 {
+		t.Errorf("same currency must not convert, get %+v %v", same, err)
+	}
+	if _, err := Convert([]plot.Candle{{Date: date(2020, time.May, 1), Close: 1}}, source, "USD", "EUR"); err == nil {
+		t.Error("expected noRate error")
+	}
+	if _, err := Convert(candles, source, "USD", "GBP"); err == nil {
+		t.Error("expected rate source error")
+	}
+}
@@ -0,0 +1,13 @@
+package httpclient
+
+import (
+	"net/http"
+	"time"
+)
+
+// Время ожидания ответа внешнего сервиса по умолчанию
+const DefaultTimeout = 30 * time.Second
+
+// HTTP клиент по умолчанию для обращений к Alpha Vantage и Yahoo Finance, ограничивает время запроса
+// чтобы медленный ответ не подвешивал обработчик
+var Default = &http.Client{Timeout: DefaultTimeout}
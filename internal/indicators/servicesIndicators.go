@@ -0,0 +1,316 @@
+package indicators
+
+import (
+	"InvestmentHelpver_V2/internal/plot"
+
+	"errors"
+	"math"
+	"strings"
+	"time"
+)
+
+// Структура Point содержит значение индикатора на дату свечи
+type Point struct {
+	Date  time.Time // дата свечи на которой посчитано значение
+	Value float64   // значение индикатора
+}
+
+// Структура MACDPoint содержит значения линии MACD, сигнальной линии и гистограммы на дату свечи
+type MACDPoint struct {
+	Date      time.Time // дата свечи на которой посчитано значение
+	MACD      float64   // разница быстрой и медленной EMA
+	Signal    float64   // EMA от линии MACD
+	Histogram float64   // разница линии MACD и сигнальной линии
+}
+
+// Структура BandPoint содержит значения полос Боллинджера на дату свечи
+type BandPoint struct {
+	Date   time.Time // дата свечи на которой посчитано значение
+	Middle float64   // скользящая средняя
+	Upper  float64   // верхняя полоса
+	Lower  float64   // нижняя полоса
+}
+
+// Периоды индикаторов по умолчанию, используются если период не передан
+const (
+	DefaultPeriod          = 20
+	DefaultRSIPeriod       = 14
+	DefaultATRPeriod       = 14
+	DefaultMACDFast        = 12
+	DefaultMACDSlow        = 26
+	DefaultMACDSignal      = 9
+	DefaultBollingerFactor = 2.0
+)
+
+var errWrongPeriod = errors.New("wrongPeriod")
+var errNotEnoughCandles = errors.New("notEnoughCandles")
+
+// Метод рассчитывающий индикатор по его названию (sma, ema, rsi, macd, bollinger, atr, obv, vwap),
+// period равный 0 означает период по умолчанию, для macd, obv и vwap period не используется
+func Calculate(name string, candles []plot.Candle, period int) (interface{}, error) {
+	switch strings.ToLower(name) {
+	case "sma":
+		return SMA(candles, defaultPeriod(period, DefaultPeriod))
+	case "ema":
+		return EMA(candles, defaultPeriod(period, DefaultPeriod))
+	case "rsi":
+		return RSI(candles, defaultPeriod(period, DefaultRSIPeriod))
+	case "macd":
+		return MACD(candles, DefaultMACDFast, DefaultMACDSlow, DefaultMACDSignal)
+	case "bollinger":
+		return BollingerBands(candles, defaultPeriod(period, DefaultPeriod), DefaultBollingerFactor)
+	case "atr":
+		return ATR(candles, defaultPeriod(period, DefaultATRPeriod))
+	case "obv":
+		return OBV(candles), nil
+	case "vwap":
+		return VWAP(candles), nil
+	}
+	return nil, errors.New("wrongIndicator")
+}
+
+// Метод рассчитывающий простую скользящую среднюю цен закрытия,
+// первое значение приходится на свечу с индексом period-1
+func SMA(candles []plot.Candle, period int) ([]Point, error) {
+	if err := checkPeriod(len(candles), period); err != nil {
+		return nil, err
+	}
+	values := smaValues(closes(candles), period)
+	return toPoints(candles[period-1:], values), nil
+}
+
+// Метод рассчитывающий экспоненциальную скользящую среднюю цен закрытия,
+// первое значение равно SMA за period свечей и приходится на свечу с индексом period-1
+func EMA(candles []plot.Candle, period int) ([]Point, error) {
+	if err := checkPeriod(len(candles), period); err != nil {
+		return nil, err
+	}
+	values := emaValues(closes(candles), period)
+	return toPoints(candles[period-1:], values), nil
+}
+
+// Метод рассчитывающий индекс относительной силы по методу Уайлдера,
+// первое значение приходится на свечу с индексом period
+func RSI(candles []plot.Candle, period int) ([]Point, error) {
+	if err := checkPeriod(len(candles)-1, period); err != nil {
+		return nil, err
+	}
+	var gain, loss float64
+	for i := 1; i <= period; i++ {
+		change := candles[i].Close - candles[i-1].Close
+		if change > 0 {
+			gain += change
+		} else {
+			loss -= change
+		}
+	}
+	gain /= float64(period)
+	loss /= float64(period)
+	points := []Point{{candles[period].Date, rsiValue(gain, loss)}}
+	for i := period + 1; i < len(candles); i++ {
+		change := candles[i].Close - candles[i-1].Close
+		gain = (gain*float64(period-1) + math.Max(change, 0)) / float64(period)
+		loss = (loss*float64(period-1) + math.Max(-change, 0)) / float64(period)
+		points = append(points, Point{candles[i].Date, rsiValue(gain, loss)})
+	}
+	return points, nil
+}
+
+// Метод рассчитывающий MACD как разницу быстрой и медленной EMA цен закрытия и сигнальную линию как EMA от MACD,
+// первое значение приходится на свечу с индексом slow+signal-2
+func MACD(candles []plot.Candle, fast, slow, signal int) ([]MACDPoint, error) {
+	if fast <= 0 || signal <= 0 || fast >= slow {
+		return nil, errWrongPeriod
+	}
+	if err := checkPeriod(len(candles)-signal+1, slow); err != nil {
+		return nil, err
+	}
+	prices := closes(candles)
+	fastEMA := emaValues(prices, fast)[slow-fast:]
+	slowEMA := emaValues(prices, slow)
+	macd := make([]float64, len(slowEMA))
+	for i := range slowEMA {
+		macd[i] = fastEMA[i] - slowEMA[i]
+	}
+	signalEMA := emaValues(macd, signal)
+	offset := slow + signal - 2
+	points := make([]MACDPoint, len(signalEMA))
+	for i := range signalEMA {
+		line := macd[i+signal-1]
+		points[i] = MACDPoint{candles[i+offset].Date, line, signalEMA[i], line - signalEMA[i]}
+	}
+	return points, nil
+}
+
+// Метод рассчитывающий полосы Боллинджера: SMA цен закрытия и полосы на расстоянии factor стандартных отклонений,
+// первое значение приходится на свечу с индексом period-1
+func BollingerBands(candles []plot.Candle, period int, factor float64) ([]BandPoint, error) {
+	if err := checkPeriod(len(candles), period); err != nil {
+		return nil, err
+	}
+	prices := closes(candles)
+	middle := smaValues(prices, period)
+	points := make([]BandPoint, len(middle))
+	for i := range middle {
+		var variance float64
+		for _, price := range prices[i : i+period] {
+			variance += (price - middle[i]) * (price - middle[i])
+		}
+		deviation := math.Sqrt(variance / float64(period))
+		points[i] = BandPoint{candles[i+period-1].Date, middle[i], middle[i] + factor*deviation, middle[i] - factor*deviation}
+	}
+	return points, nil
+}
+
+// Метод рассчитывающий средний истинный диапазон по методу Уайлдера,
+// первое значение приходится на свечу с индексом period
+func ATR(candles []plot.Candle, period int) ([]Point, error) {
+	if err := checkPeriod(len(candles)-1, period); err != nil {
+		return nil, err
+	}
+	var atr float64
+	for i := 1; i <= period; i++ {
+		atr += trueRange(candles[i], candles[i-1])
+	}
+	atr /= float64(period)
+	points := []Point{{candles[period].Date, atr}}
+	for i := period + 1; i < len(candles); i++ {
+		atr = (atr*float64(period-1) + trueRange(candles[i], candles[i-1])) / float64(period)
+		points = append(points, Point{candles[i].Date, atr})
+	}
+	return points, nil
+}
+
+// Метод рассчитывающий балансовый объем (On Balance Volume), первое значение равно 0
+func OBV(candles []plot.Candle) []Point {
+	points := make([]Point, len(candles))
+	var obv float64
+	for i, candle := range candles {
+		if i > 0 {
+			switch {
+			case candle.Close > candles[i-1].Close:
+				obv += float64(candle.Volume)
+			case candle.Close < candles[i-1].Close:
+				obv -= float64(candle.Volume)
+			}
+		}
+		points[i] = Point{candle.Date, obv}
+	}
+	return points
+}
+
+// Метод рассчитывающий среднюю цену взвешенную по объему по типичной цене (high+low+close)/3,
+// для внутридневных свечей расчет начинается заново в каждой торговой сессии, для остальных накапливается по всему ряду
+func VWAP(candles []plot.Candle) []Point {
+	intraday := false
+	for i := 1; i < len(candles); i++ {
+		if sameDay(candles[i].Date, candles[i-1].Date) {
+			intraday = true
+			break
+		}
+	}
+	points := make([]Point, len(candles))
+	var priceVolume, volume float64
+	for i, candle := range candles {
+		if intraday && i > 0 && !sameDay(candle.Date, candles[i-1].Date) {
+			priceVolume, volume = 0, 0
+		}
+		typical := (candle.High + candle.Low + candle.Close) / 3
+		priceVolume += typical * float64(candle.Volume)
+		volume += float64(candle.Volume)
+		value := typical
+		if volume > 0 {
+			value = priceVolume / volume
+		}
+		points[i] = Point{candle.Date, value}
+	}
+	return points
+}
+
+// Вспомогательный метод возвращающий период по умолчанию если period не передан
+func defaultPeriod(period, def int) int {
+	if period == 0 {
+		return def
+	}
+	return period
+}
+
+// Вспомогательный метод проверяющий что период положительный и свечей достаточно для расчета
+func checkPeriod(length, period int) error {
+	if period <= 0 {
+		return errWrongPeriod
+	}
+	if length < period {
+		return errNotEnoughCandles
+	}
+	return nil
+}
+
+// Вспомогательный метод возвращающий список цен закрытия
+func closes(candles []plot.Candle) []float64 {
+	prices := make([]float64, len(candles))
+	for i, candle := range candles {
+		prices[i] = candle.Close
+	}
+	return prices
+}
+
+// Вспомогательный метод рассчитывающий SMA, возвращает len(values)-period+1 значений
+func smaValues(values []float64, period int) []float64 {
+	result := make([]float64, 0, len(values)-period+1)
+	var sum float64
+	for i, value := range values {
+		sum += value
+		if i >= period {
+			sum -= values[i-period]
+		}
+		if i >= period-1 {
+			result = append(result, sum/float64(period))
+		}
+	}
+	return result
+}
+
+// Вспомогательный метод рассчитывающий EMA с начальным значением равным SMA, возвращает len(values)-period+1 значений
+func emaValues(values []float64, period int) []float64 {
+	k := 2 / float64(period+1)
+	result := make([]float64, 0, len(values)-period+1)
+	var sum float64
+	for _, value := range values[:period] {
+		sum += value
+	}
+	ema := sum / float64(period)
+	result = append(result, ema)
+	for _, value := range values[period:] {
+		ema = (value-ema)*k + ema
+		result = append(result, ema)
+	}
+	return result
+}
+
+// Вспомогательный метод рассчитывающий RSI по средним росту и падению
+func rsiValue(gain, loss float64) float64 {
+	if loss == 0 {
+		return 100
+	}
+	return 100 - 100/(1+gain/loss)
+}
+
+// Вспомогательный метод рассчитывающий истинный диапазон свечи
+func trueRange(candle, previous plot.Candle) float64 {
+	return math.Max(candle.High-candle.Low, math.Max(math.Abs(candle.High-previous.Close), math.Abs(candle.Low-previous.Close)))
+}
+
+// Вспомогательный метод проверяющий что две даты приходятся на один день
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// Вспомогательный метод соединяющий даты свечей со значениями индикатора
+func toPoints(candles []plot.Candle, values []float64) []Point {
+	points := make([]Point, len(values))
+	for i, value := range values {
+		points[i] = Point{candles[i].Date, value}
+	}
+	return points
+}
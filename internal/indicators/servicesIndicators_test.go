@@ -0,0 +1,148 @@
+package indicators
+
+import (
+	"InvestmentHelpver_V2/internal/plot"
+	"InvestmentHelpver_V2/internal/plot/plottest"
+
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// Вспомогательный метод сравнивающий значения индикатора с эталонными
+func checkValues(t *testing.T, points []Point, want []float64) {
+	t.Helper()
+	if len(points) != len(want) {
+		t.Fatalf("wrong length, want %d, get %d", len(want), len(points))
+	}
+	for i := range want {
+		if math.Abs(points[i].Value-want[i]) > 1e-4 {
+			t.Errorf("wrong value %d, want %f, get %f", i, want[i], points[i].Value)
+		}
+	}
+}
+
+func TestMovingAverages(t *testing.T) {
+	emaPrices := []float64{22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24, 22.29, 22.15, 22.39, 22.38, 22.61}
+	tests := []struct {
+		name      string
+		indicator func([]plot.Candle, int) ([]Point, error)
+		prices    []float64
+		period    int
+		want      []float64
+	}{
+		{"sma", SMA, []float64{1, 2, 3, 4, 5}, 3, []float64{2, 3, 4}},
+		{"sma period 1", SMA, []float64{1, 2, 3}, 1, []float64{1, 2, 3}},
+		{"ema", EMA, emaPrices, 10, []float64{22.221, 22.2081, 22.2412, 22.2664, 22.3289}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			points, err := test.indicator(plottest.Candles(test.prices...), test.period)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkValues(t, points, test.want)
+		})
+	}
+}
+
+func TestRSI(t *testing.T) {
+	candles := plottest.Candles(44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08,
+		45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64)
+	points, err := RSI(candles, 14)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkValues(t, points, []float64{70.4641, 66.2496, 66.4809, 69.3469, 66.2947, 57.9150})
+	if !points[0].Date.Equal(candles[14].Date) {
+		t.Errorf("wrong first date, want %s, get %s", candles[14].Date, points[0].Date)
+	}
+}
+
+func TestMACD(t *testing.T) {
+	points, err := MACD(plottest.Candles(1, 2, 4, 8, 16, 32, 64), 2, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []MACDPoint{
+		{MACD: 1.222222, Signal: 1.027778, Histogram: 0.194444},
+		{MACD: 2.212963, Signal: 1.817901, Histogram: 0.395062},
+		{MACD: 4.307099, Signal: 3.477366, Histogram: 0.829733},
+		{MACD: 8.553755, Signal: 6.861626, Histogram: 1.692130},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("wrong length, want %d, get %d", len(want), len(points))
+	}
+	for i := range want {
+		if math.Abs(points[i].MACD-want[i].MACD) > 1e-4 || math.Abs(points[i].Signal-want[i].Signal) > 1e-4 ||
+			math.Abs(points[i].Histogram-want[i].Histogram) > 1e-4 {
+			t.Errorf("wrong value %d, want %+v, get %+v", i, want[i], points[i])
+		}
+	}
+}
+
+func TestBollingerBands(t *testing.T) {
+	points, err := BollingerBands(plottest.Candles(1, 2, 3, 4, 5), 5, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 1 {
+		t.Fatalf("wrong length, want 1, get %d", len(points))
+	}
+	if points[0].Middle != 3 || math.Abs(points[0].Upper-5.828427) > 1e-4 || math.Abs(points[0].Lower-0.171573) > 1e-4 {
+		t.Errorf("wrong bands %+v", points[0])
+	}
+}
+
+func TestATR(t *testing.T) {
+	highs := []float64{10, 11, 12, 11, 13}
+	lows := []float64{9, 10, 10, 9, 11}
+	candles := plottest.Candles(9.5, 10.5, 11.5, 10, 12.5)
+	for i := range candles {
+		candles[i].High, candles[i].Low = highs[i], lows[i]
+	}
+	points, err := ATR(candles, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkValues(t, points, []float64{1.75, 2.125, 2.5625})
+}
+
+func TestOBVAndVWAP(t *testing.T) {
+	candles := plottest.Candles(10, 11, 10.5, 10.5)
+	for i := range candles {
+		candles[i].Volume = (i + 1) * 100
+	}
+	checkValues(t, OBV(candles), []float64{0, 200, -100, -100})
+	checkValues(t, VWAP(candles), []float64{10, 10.6667, 10.5833, 10.55})
+
+	intraday := plottest.Candles(10, 12, 20)
+	intraday[1].Date = intraday[0].Date.Add(time.Hour)
+	checkValues(t, VWAP(intraday), []float64{10, 11, 20})
+}
+
+func TestCalculate(t *testing.T) {
+	candles := plottest.Candles(1, 2, 3, 4, 5, 6, 7, 8, 9, 10)
+	tests := []struct {
+		name    string
+		period  int
+		wantErr bool
+	}{
+		{"sma", 3, false},
+		{"RSI", 5, false},
+		{"obv", 0, false},
+		{"sma", 11, true},
+		{"ema", -1, true},
+		{"macd", 0, true},
+		{"unknown", 3, true},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test indicator:%s period:%d", test.name, test.period), func(t *testing.T) {
+			_, err := Calculate(test.name, candles, test.period)
+			if (err != nil) != test.wantErr {
+				t.Errorf("wrong error, get %v", err)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package news
 
 import (
+	"InvestmentHelpver_V2/internal/httpclient"
+
 	"github.com/PuerkitoBio/goquery"
 
 	"errors"
@@ -22,23 +24,42 @@ type NewsManager interface {
 	GetNews(string) ([]News, error) // принимает символ финансового актива, возвращать список новостей в виде списка экземпляров структуры News
 }
 
+// Адрес сайта Yahoo Finance по умолчанию
+const DefaultYahooURL = "https://finance.yahoo.com"
+
 // Реализация интерфейса NewsManager, отвечает за получение новостей с сайта https://finance.yahoo.com
+// (или с другого адреса BaseURL, например прокси)
 type NewsManagerYahoo struct {
+	BaseURL    string
+	HTTPClient *http.Client
 }
 
-// Конструктор для структуры NewsManagerYahoo
+// Конструктор для структуры NewsManagerYahoo, использует адрес и HTTP клиент по умолчанию
 func NewNewsManagerYahoo() NewsManager {
-	newsManager := NewsManagerYahoo{}
+	return NewNewsManagerYahooWithClient(DefaultYahooURL, nil)
+}
+
+// Конструктор для структуры NewsManagerYahoo, принимает адрес сайта и HTTP клиент (таймауты, прокси, транспорт),
+// пустой baseURL заменяется адресом по умолчанию, nil httpClient - клиентом httpclient.Default
+func NewNewsManagerYahooWithClient(baseURL string, httpClient *http.Client) NewsManager {
+	if baseURL == "" {
+		baseURL = DefaultYahooURL
+	}
+	if httpClient == nil {
+		httpClient = httpclient.Default
+	}
+	newsManager := NewsManagerYahoo{strings.TrimSuffix(baseURL, "/"), httpClient}
 	return newsManager
 }
 
 // Метод структуры NewsManagerYahoo, принимает символ финансового актива, возвращает список экземпляров структуры News
 func (newsManager NewsManagerYahoo) GetNews(symbol string) ([]News, error) {
-	url := fmt.Sprintf("https://finance.yahoo.com/quote/%s/news?p=%s", symbol, symbol)
-	resp, err := http.Get(url)
+	url := fmt.Sprintf("%s/quote/%s/news?p=%s", newsManager.BaseURL, symbol, symbol)
+	resp, err := newsManager.HTTPClient.Get(url)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	html, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
 		return nil, err
@@ -50,7 +71,7 @@ func (newsManager NewsManagerYahoo) GetNews(symbol string) ([]News, error) {
 			headline := selection.Text()
 			link, _ := selection.Attr("href")
 			if !strings.Contains(link, "http") {
-				link = "https://finance.yahoo.com" + link
+				link = newsManager.BaseURL + link
 			}
 			news = append(news, News{headline, link})
 		}
@@ -2,7 +2,10 @@ package news
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestGetNewsYahoo(t *testing.T) {
@@ -29,3 +32,55 @@ func TestGetNewsYahoo(t *testing.T) {
 		}
 	})
 }
+
+func TestGetNewsYahooLocalServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/quote/IBM/news" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `<html><body>
+<a href="/news/ibm-earnings.html"><div class="StretchedBox"></div>IBM earnings</a>
+<a href="https://example.com/ibm-cloud.html"><div class="StretchedBox"></div>IBM cloud</a>
+<a href="/quote/IBM">IBM quote</a>
+</body></html>`)
+	}))
+	defer server.Close()
+	newsManagerTest := NewNewsManagerYahooWithClient(server.URL, server.Client())
+
+	t.Run("test news from local server", func(t *testing.T) {
+		news, err := newsManagerTest.GetNews("IBM")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []News{
+			{"IBM earnings", server.URL + "/news/ibm-earnings.html"},
+			{"IBM cloud", "https://example.com/ibm-cloud.html"},
+		}
+		if len(news) != len(want) {
+			t.Fatalf("wrong news count, want %d, get %d", len(want), len(news))
+		}
+		for i := range want {
+			if news[i] != want[i] {
+				t.Errorf("wrong news %d, want %+v, get %+v", i, want[i], news[i])
+			}
+		}
+	})
+
+	t.Run("test empty news from local server", func(t *testing.T) {
+		news, err := newsManagerTest.GetNews("unrealSymbol")
+		if err == nil || len(news) != 0 {
+			t.Errorf("expected error, get %v %v", news, err)
+		}
+	})
+}
+
+func TestNewNewsManagerYahooWithClient(t *testing.T) {
+	newsManagerTest := NewNewsManagerYahooWithClient("", nil).(NewsManagerYahoo)
+	if newsManagerTest.BaseURL != DefaultYahooURL {
+		t.Errorf("wrong base url %s", newsManagerTest.BaseURL)
+	}
+	if newsManagerTest.HTTPClient == nil || newsManagerTest.HTTPClient.Timeout != 30*time.Second {
+		t.Errorf("wrong default http client %+v", newsManagerTest.HTTPClient)
+	}
+}
@@ -0,0 +1,202 @@
+package patterns
+
+import (
+	"InvestmentHelpver_V2/internal/plot"
+
+	"math"
+	"time"
+)
+
+// Направление сигнала свечной модели
+type Bias string
+
+const (
+	BiasBullish Bias = "bullish"
+	BiasBearish Bias = "bearish"
+	BiasNeutral Bias = "neutral"
+)
+
+// Названия свечных моделей
+const (
+	PatternDoji               = "doji"
+	PatternHammer             = "hammer"
+	PatternShootingStar       = "shootingStar"
+	PatternBullishEngulfing   = "bullishEngulfing"
+	PatternBearishEngulfing   = "bearishEngulfing"
+	PatternBullishHarami      = "bullishHarami"
+	PatternBearishHarami      = "bearishHarami"
+	PatternMorningStar        = "morningStar"
+	PatternEveningStar        = "eveningStar"
+	PatternThreeWhiteSoldiers = "threeWhiteSoldiers"
+	PatternThreeBlackCrows    = "threeBlackCrows"
+)
+
+// Структура Match содержит найденную свечную модель: Index и Date - индекс и дата последней свечи модели в графике,
+// Length - количество свечей модели
+type Match struct {
+	Pattern string
+	Date    time.Time
+	Index   int
+	Length  int
+	Bias    Bias
+}
+
+// Пороговые значения моделей в долях тела или диапазона свечи
+const (
+	dojiBody      = 0.1 // тело доджи не больше 10% диапазона
+	shadowFactor  = 2.0 // длинная тень молота и падающей звезды не меньше двух тел
+	shortShadow   = 0.1 // короткая тень не больше 10% диапазона
+	longBody      = 0.5 // длинное тело не меньше половины диапазона
+	starBody      = 0.3 // тело звезды не больше 30% тела первой свечи
+	soldierShadow = 0.3 // верхняя (нижняя у ворон) тень солдат не больше 30% тела
+	trendLookback = 3   // тренд перед молотом и падающей звездой определяется по закрытию trendLookback свечей назад
+)
+
+// Метод находящий свечные модели в отсортированном графике, возвращает их в порядке индекса последней свечи модели.
+// Молот ищется только после снижения, падающая звезда только после роста
+func Detect(candles []plot.Candle) []Match {
+	matches := []Match{}
+	add := func(i, length int, pattern string, bias Bias) {
+		matches = append(matches, Match{pattern, candles[i].Date, i, length, bias})
+	}
+	for i, candle := range candles {
+		if isDoji(candle) {
+			add(i, 1, PatternDoji, BiasNeutral)
+		}
+		if isHammer(candle) && trend(candles, i) < 0 {
+			add(i, 1, PatternHammer, BiasBullish)
+		}
+		if isShootingStar(candle) && trend(candles, i) > 0 {
+			add(i, 1, PatternShootingStar, BiasBearish)
+		}
+		if i >= 1 {
+			previous := candles[i-1]
+			switch {
+			case isEngulfing(previous, candle) && isBullish(candle):
+				add(i, 2, PatternBullishEngulfing, BiasBullish)
+			case isEngulfing(previous, candle) && isBearish(candle):
+				add(i, 2, PatternBearishEngulfing, BiasBearish)
+			case isHarami(previous, candle) && isBullish(candle):
+				add(i, 2, PatternBullishHarami, BiasBullish)
+			case isHarami(previous, candle) && isBearish(candle):
+				add(i, 2, PatternBearishHarami, BiasBearish)
+			}
+		}
+		if i >= 2 {
+			first, second := candles[i-2], candles[i-1]
+			switch {
+			case isMorningStar(first, second, candle):
+				add(i, 3, PatternMorningStar, BiasBullish)
+			case isEveningStar(first, second, candle):
+				add(i, 3, PatternEveningStar, BiasBearish)
+			case isThreeWhiteSoldiers(first, second, candle):
+				add(i, 3, PatternThreeWhiteSoldiers, BiasBullish)
+			case isThreeBlackCrows(first, second, candle):
+				add(i, 3, PatternThreeBlackCrows, BiasBearish)
+			}
+		}
+	}
+	return matches
+}
+
+// Вспомогательные методы возвращающие размеры частей свечи
+func body(candle plot.Candle) float64 { return math.Abs(candle.Close - candle.Open) }
+
+func bodyHigh(candle plot.Candle) float64 { return math.Max(candle.Open, candle.Close) }
+
+func bodyLow(candle plot.Candle) float64 { return math.Min(candle.Open, candle.Close) }
+
+func candleRange(candle plot.Candle) float64 { return candle.High - candle.Low }
+
+func upperShadow(candle plot.Candle) float64 { return candle.High - bodyHigh(candle) }
+
+func lowerShadow(candle plot.Candle) float64 { return bodyLow(candle) - candle.Low }
+
+func isBullish(candle plot.Candle) bool { return candle.Close > candle.Open }
+
+func isBearish(candle plot.Candle) bool { return candle.Close < candle.Open }
+
+func isLong(candle plot.Candle) bool {
+	return body(candle) >= longBody*candleRange(candle) && body(candle) > 0
+}
+
+// Вспомогательный метод возвращающий направление тренда перед свечей i: -1 снижение, 1 рост, 0 недостаточно свечей
+func trend(candles []plot.Candle, i int) int {
+	if i < trendLookback {
+		return 0
+	}
+	switch {
+	case candles[i-1].Close < candles[i-trendLookback].Close:
+		return -1
+	case candles[i-1].Close > candles[i-trendLookback].Close:
+		return 1
+	}
+	return 0
+}
+
+// Доджи: цены открытия и закрытия почти равны
+func isDoji(candle plot.Candle) bool {
+	return candleRange(candle) > 0 && body(candle) <= dojiBody*candleRange(candle)
+}
+
+// Молот: маленькое тело у верхней границы и длинная нижняя тень (у плоской свечи без движения цены теней нет)
+func isHammer(candle plot.Candle) bool {
+	return candleRange(candle) > 0 && !isDoji(candle) && lowerShadow(candle) > 0 &&
+		lowerShadow(candle) >= shadowFactor*body(candle) && upperShadow(candle) <= shortShadow*candleRange(candle)
+}
+
+// Падающая звезда: маленькое тело у нижней границы и длинная верхняя тень (у плоской свечи без движения цены теней нет)
+func isShootingStar(candle plot.Candle) bool {
+	return candleRange(candle) > 0 && !isDoji(candle) && upperShadow(candle) > 0 &&
+		upperShadow(candle) >= shadowFactor*body(candle) && lowerShadow(candle) <= shortShadow*candleRange(candle)
+}
+
+// Поглощение: тело второй свечи противоположного цвета полностью перекрывает тело первой
+func isEngulfing(previous, candle plot.Candle) bool {
+	return isBullish(previous) != isBullish(candle) && !isDoji(previous) && body(candle) > body(previous) &&
+		bodyHigh(candle) >= bodyHigh(previous) && bodyLow(candle) <= bodyLow(previous)
+}
+
+// Харами: тело второй свечи противоположного цвета находится внутри длинного тела первой
+func isHarami(previous, candle plot.Candle) bool {
+	return isBullish(previous) != isBullish(candle) && isLong(previous) && body(candle) > 0 && body(candle) < body(previous) &&
+		bodyHigh(candle) <= bodyHigh(previous) && bodyLow(candle) >= bodyLow(previous)
+}
+
+// Утренняя звезда: длинная падающая свеча, свеча с маленьким телом ниже ее тела и растущая свеча закрывшаяся выше середины первой
+func isMorningStar(first, second, third plot.Candle) bool {
+	return isBearish(first) && isLong(first) && body(second) <= starBody*body(first) && bodyHigh(second) <= first.Close &&
+		isBullish(third) && third.Close > (first.Open+first.Close)/2
+}
+
+// Вечерняя звезда: длинная растущая свеча, свеча с маленьким телом выше ее тела и падающая свеча закрывшаяся ниже середины первой
+func isEveningStar(first, second, third plot.Candle) bool {
+	return isBullish(first) && isLong(first) && body(second) <= starBody*body(first) && bodyLow(second) >= first.Close &&
+		isBearish(third) && third.Close < (first.Open+first.Close)/2
+}
+
+// Три белых солдата: три растущие свечи, каждая открывается внутри тела предыдущей и закрывается выше нее с короткой верхней тенью
+func isThreeWhiteSoldiers(candles ...plot.Candle) bool {
+	for i, candle := range candles {
+		if !isBullish(candle) || upperShadow(candle) > soldierShadow*body(candle) {
+			return false
+		}
+		if i > 0 && (candle.Close <= candles[i-1].Close || candle.Open < candles[i-1].Open || candle.Open > candles[i-1].Close) {
+			return false
+		}
+	}
+	return true
+}
+
+// Три черные вороны: три падающие свечи, каждая открывается внутри тела предыдущей и закрывается ниже нее с короткой нижней тенью
+func isThreeBlackCrows(candles ...plot.Candle) bool {
+	for i, candle := range candles {
+		if !isBearish(candle) || lowerShadow(candle) > soldierShadow*body(candle) {
+			return false
+		}
+		if i > 0 && (candle.Close >= candles[i-1].Close || candle.Open > candles[i-1].Open || candle.Open < candles[i-1].Close) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package patterns
+
+import (
+	"InvestmentHelpver_V2/internal/plot/plottest"
+
+	"fmt"
+	"testing"
+)
+
+// Свечи создающие снижение и рост перед моделями которые зависят от тренда
+var downtrend = [][4]float64{{14, 14.5, 12.5, 13}, {13, 13.5, 11.5, 12}, {12, 12.5, 10.5, 11}}
+var uptrend = [][4]float64{{6, 7.5, 5.5, 7}, {7, 8.5, 6.5, 8}, {8, 9.5, 7.5, 9}}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		prices  [][4]float64
+		pattern string
+		bias    Bias
+		length  int
+	}{
+		{"doji", [][4]float64{{10, 11, 9, 10.05}}, PatternDoji, BiasNeutral, 1},
+		{"hammer", append(downtrend, [4]float64{10, 10.6, 7, 10.5}), PatternHammer, BiasBullish, 1},
+		{"shooting star", append(uptrend, [4]float64{10, 13, 9.55, 9.6}), PatternShootingStar, BiasBearish, 1},
+		{"bullish engulfing", [][4]float64{{10, 10.2, 8.8, 9}, {8.8, 10.6, 8.7, 10.5}}, PatternBullishEngulfing, BiasBullish, 2},
+		{"bearish engulfing", [][4]float64{{9, 10.2, 8.8, 10}, {10.2, 10.3, 8.5, 8.6}}, PatternBearishEngulfing, BiasBearish, 2},
+		{"bullish harami", [][4]float64{{12, 12.2, 7.8, 8}, {9, 10.5, 8.8, 10}}, PatternBullishHarami, BiasBullish, 2},
+		{"bearish harami", [][4]float64{{8, 12.2, 7.8, 12}, {11, 11.2, 9.5, 10}}, PatternBearishHarami, BiasBearish, 2},
+		{"morning star", [][4]float64{{12, 12.2, 7.8, 8}, {7.5, 7.9, 7, 7.7}, {8, 11.2, 7.9, 11}}, PatternMorningStar, BiasBullish, 3},
+		{"evening star", [][4]float64{{8, 12.2, 7.8, 12}, {12.3, 13, 12.1, 12.5}, {12, 12.1, 8.8, 9}}, PatternEveningStar, BiasBearish, 3},
+		{"three white soldiers", [][4]float64{{10, 11.1, 9.9, 11}, {10.5, 12.1, 10.4, 12}, {11.5, 13.1, 11.4, 13}}, PatternThreeWhiteSoldiers, BiasBullish, 3},
+		{"three black crows", [][4]float64{{13, 13.1, 11.9, 12}, {12.5, 12.6, 10.9, 11}, {11.5, 11.6, 9.9, 10}}, PatternThreeBlackCrows, BiasBearish, 3},
+		{"flat after downtrend", append(downtrend, [4]float64{7, 7, 7, 7}, [4]float64{7, 7, 7, 7}), "", BiasNeutral, 0},
+		{"flat after uptrend", append(uptrend, [4]float64{10, 10, 10, 10}, [4]float64{10, 10, 10, 10}), "", BiasNeutral, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			candles := plottest.OHLC(test.prices...)
+			if test.pattern == "" {
+				if matches := Detect(candles); len(matches) != 0 {
+					t.Errorf("unexpected matches %+v", matches)
+				}
+				return
+			}
+			last := len(candles) - 1
+			found := false
+			for _, match := range Detect(candles) {
+				if match.Pattern == test.pattern {
+					found = true
+					if match.Index != last || !match.Date.Equal(candles[last].Date) || match.Bias != test.bias || match.Length != test.length {
+						t.Errorf("wrong match %+v", match)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("pattern %s not found in %+v", test.pattern, Detect(candles))
+			}
+		})
+	}
+}
+
+func TestDetectTrend(t *testing.T) {
+	hammer := [4]float64{10, 10.6, 7, 10.5}
+	for _, match := range Detect(plottest.OHLC(append(uptrend, hammer)...)) {
+		if match.Pattern == PatternHammer {
+			t.Error(fmt.Sprintf("hammer found after uptrend %+v", match))
+		}
+	}
+	if matches := Detect(plottest.OHLC(uptrend...)); len(matches) != 0 {
+		t.Errorf("unexpected matches %+v", matches)
+	}
+}
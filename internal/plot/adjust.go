@@ -0,0 +1,26 @@
+package plot
+
+// Метод корректирующий назад весь ряд свечей на сплиты и дивиденды.
+// Цены Open, High, Low и Close умножаются на отношение AdjustedClose/Close свечи,
+// объем умножается на произведение коэффициентов сплитов прошедших после свечи.
+// Свечи без AdjustedClose не корректируются по цене. Исходный список не изменяется
+func AdjustCandles(candles []Candle) []Candle {
+	adjusted := make([]Candle, len(candles))
+	splitFactor := 1.0
+	for i := len(candles) - 1; i >= 0; i-- {
+		candle := candles[i]
+		if candle.AdjustedClose != 0 && candle.Close != 0 {
+			factor := candle.AdjustedClose / candle.Close
+			candle.Open *= factor
+			candle.High *= factor
+			candle.Low *= factor
+			candle.Close = candle.AdjustedClose
+		}
+		candle.Volume = int(float64(candle.Volume)*splitFactor + 0.5)
+		if candle.SplitCoefficient > 0 {
+			splitFactor *= candle.SplitCoefficient
+		}
+		adjusted[i] = candle
+	}
+	return adjusted
+}
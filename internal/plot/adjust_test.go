@@ -0,0 +1,32 @@
+package plot
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAdjustCandles(t *testing.T) {
+	// сплит 2 к 1 на третьей свече и дивиденд 1 на второй
+	candles := []Candle{
+		{Date: date(2020, time.May, 11), Open: 100, High: 110, Low: 90, Close: 100, Volume: 10, AdjustedClose: 49.5, SplitCoefficient: 1},
+		{Date: date(2020, time.May, 12), Open: 100, High: 102, Low: 98, Close: 100, Volume: 20, AdjustedClose: 50, DividendAmount: 1, SplitCoefficient: 1},
+		{Date: date(2020, time.May, 13), Open: 50, High: 52, Low: 48, Close: 51, Volume: 30, AdjustedClose: 51, SplitCoefficient: 2},
+	}
+	adjusted := AdjustCandles(candles)
+	want := []Candle{
+		{Date: date(2020, time.May, 11), Open: 49.5, High: 54.45, Low: 44.55, Close: 49.5, Volume: 20, AdjustedClose: 49.5, SplitCoefficient: 1},
+		{Date: date(2020, time.May, 12), Open: 50, High: 51, Low: 49, Close: 50, Volume: 40, AdjustedClose: 50, DividendAmount: 1, SplitCoefficient: 1},
+		{Date: date(2020, time.May, 13), Open: 50, High: 52, Low: 48, Close: 51, Volume: 30, AdjustedClose: 51, SplitCoefficient: 2},
+	}
+	for i := range want {
+		get := adjusted[i]
+		if math.Abs(get.Open-want[i].Open) > 1e-9 || math.Abs(get.High-want[i].High) > 1e-9 ||
+			math.Abs(get.Low-want[i].Low) > 1e-9 || get.Close != want[i].Close || get.Volume != want[i].Volume {
+			t.Errorf("wrong candle %d, want %+v, get %+v", i, want[i], get)
+		}
+	}
+	if candles[0].Close != 100 {
+		t.Error("source candles changed")
+	}
+}
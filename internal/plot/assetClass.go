@@ -0,0 +1,204 @@
+package plot
+
+import (
+	"InvestmentHelpver_V2/internal/alphavantage"
+
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Класс финансового актива: equity - акции и фонды, fx - валютные пары, crypto - криптовалюты
+type AssetClass string
+
+const (
+	AssetClassEquity AssetClass = "equity"
+	AssetClassFX     AssetClass = "fx"
+	AssetClassCrypto AssetClass = "crypto"
+)
+
+// Валюта в которой по умолчанию котируются криптовалюты если в символе не указан рынок
+const defaultCryptoMarket = "USD"
+
+// Метод превращающий строку из запроса в AssetClass, пустая строка возвращается как есть и означает класс по символу
+func ParseAssetClass(s string) (AssetClass, error) {
+	assetClass := AssetClass(strings.ToLower(s))
+	switch assetClass {
+	case "", AssetClassEquity, AssetClassFX, AssetClassCrypto:
+		return assetClass, nil
+	}
+	return "", errors.New("wrongAssetClass")
+}
+
+// Распространенные криптовалюты, пара с такой базовой валютой без явного класса считается криптовалютой
+var knownCryptoCurrencies = map[string]bool{
+	"BTC": true, "ETH": true, "LTC": true, "XRP": true, "BCH": true, "ADA": true, "DOT": true, "SOL": true,
+	"DOGE": true, "BNB": true, "XLM": true, "LINK": true, "TRX": true, "EOS": true, "XMR": true, "ETC": true,
+	"USDT": true, "USDC": true,
+}
+
+// Метод определяющий класс актива: явно указанный класс возвращается как есть, символ вида BTC/USD с известной
+// криптовалютой в базе считается криптовалютой, остальные символы вида EUR/USD - валютными парами, прочие символы - акциями
+// (криптовалюту без рынка, например BTC, или неизвестную криптовалюту нужно указывать с классом crypto)
+func ResolveAssetClass(symbol string, assetClass AssetClass) AssetClass {
+	switch {
+	case assetClass != "":
+		return assetClass
+	case strings.Contains(symbol, "/"):
+		base := strings.ToUpper(strings.SplitN(symbol, "/", 2)[0])
+		if knownCryptoCurrencies[base] {
+			return AssetClassCrypto
+		}
+		return AssetClassFX
+	}
+	return AssetClassEquity
+}
+
+// Метод разбирающий символ валютной пары или криптовалюты вида BASE/QUOTE (EUR/USD, BTC/EUR) на две валюты,
+// у криптовалюты без указанного рынка (BTC) рынком считается USD
+func SplitPair(symbol string, assetClass AssetClass) (string, string, error) {
+	parts := strings.Split(strings.ToUpper(symbol), "/")
+	switch {
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+		return parts[0], parts[1], nil
+	case len(parts) == 1 && parts[0] != "" && assetClass == AssetClassCrypto:
+		return parts[0], defaultCryptoMarket, nil
+	}
+	return "", "", errors.New("wrongPair")
+}
+
+// Вспомогательный метод возвращающий название функции Alpha Vantage для валютных пар и криптовалют
+func (interval Interval) currencyFunction(assetClass AssetClass) string {
+	prefix := "FX_"
+	if assetClass == AssetClassCrypto {
+		if interval.IsIntraday() {
+			return "CRYPTO_INTRADAY"
+		}
+		prefix = "DIGITAL_CURRENCY_"
+	}
+	switch interval {
+	case IntervalWeekly:
+		return prefix + "WEEKLY"
+	case IntervalMonthly:
+		return prefix + "MONTHLY"
+	}
+	if interval.IsIntraday() {
+		return "FX_INTRADAY"
+	}
+	return prefix + "DAILY"
+}
+
+// Вспомогательный метод возвращающий параметры запроса Alpha Vantage для валютной пары или криптовалюты
+func currencyParams(symbol string, options PlotOptions) (url.Values, error) {
+	base, quote, err := SplitPair(symbol, options.AssetClass)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("function", options.Interval.currencyFunction(options.AssetClass))
+	if options.AssetClass == AssetClassCrypto {
+		params.Set("symbol", base)
+		params.Set("market", quote)
+	} else {
+		params.Set("from_symbol", base)
+		params.Set("to_symbol", quote)
+	}
+	if options.Interval.IsIntraday() {
+		params.Set("interval", string(options.Interval))
+	}
+	if options.AssetClass == AssetClassFX && options.NeedFullOutput(time.Now()) {
+		params.Set("outputsize", "full")
+	}
+	return params, nil
+}
+
+// Вспомогательный метод возвращающий ключ временного ряда в ответе Alpha Vantage для валютной пары или криптовалюты
+func currencySeriesKey(interval Interval, assetClass AssetClass) string {
+	name := "Daily"
+	switch {
+	case interval.IsIntraday():
+		name = string(interval)
+	case interval == IntervalWeekly:
+		name = "Weekly"
+	case interval == IntervalMonthly:
+		name = "Monthly"
+	}
+	switch {
+	case assetClass == AssetClassFX:
+		return "Time Series FX (" + name + ")"
+	case interval.IsIntraday():
+		return "Time Series Crypto (" + name + ")"
+	}
+	return "Time Series (Digital Currency " + name + ")"
+}
+
+// Метод принимающий тело ответа FX_* или DIGITAL_CURRENCY_* Alpha Vantage и параметры запроса, превращающий его в список структуры Candle,
+// у валютных пар объем торгов отсутствует и равен 0, объем криптовалют округляется до целого.
+// Поддерживаются оба формата свечей криптовалют: 1. open и старый 1a. open (USD)
+func ScrapCurrencyJSONBody(body string, options PlotOptions) ([]Candle, error) {
+	interval := options.Interval
+	var response map[string]json.RawMessage
+	err := json.Unmarshal([]byte(body), &response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+	}
+	seriesKey := currencySeriesKey(interval, options.AssetClass)
+	raw, ok := response[seriesKey]
+	if !ok {
+		return nil, fmt.Errorf("%w: no %s", alphavantage.ErrMalformedResponse, seriesKey)
+	}
+	var timeSeries map[string]map[string]string
+	err = json.Unmarshal(raw, &timeSeries)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+	}
+	days := make([]Candle, 0, len(timeSeries))
+	for dateKey, values := range timeSeries {
+		date, err := time.Parse(interval.dateLayout(), dateKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+		}
+		day, err := currencyCandle(date, values)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", alphavantage.ErrMalformedResponse, dateKey, err)
+		}
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date.Before(days[j].Date) })
+	return days, nil
+}
+
+// Вспомогательный метод превращающий свечу валютной пары или криптовалюты из ответа Alpha Vantage в Candle,
+// в старом формате криптовалют цены в валюте рынка находятся под ключами 1a-4a
+func currencyCandle(date time.Time, values map[string]string) (Candle, error) {
+	field := func(number, name string) string {
+		if value, ok := values[number+". "+name]; ok {
+			return value
+		}
+		for key, value := range values {
+			if strings.HasPrefix(key, number+"a. "+name+" (") {
+				return value
+			}
+		}
+		return ""
+	}
+	prices, err := GetFloatPrices(field("1", "open"), field("2", "high"), field("3", "low"), field("4", "close"))
+	if err != nil {
+		return Candle{}, err
+	}
+	day := Candle{Date: date, Open: prices[0], High: prices[1], Low: prices[2], Close: prices[3]}
+	if volumeS := field("5", "volume"); volumeS != "" {
+		volume, err := strconv.ParseFloat(volumeS, 64)
+		if err != nil {
+			return Candle{}, err
+		}
+		day.Volume = int(math.Round(volume))
+	}
+	return day, nil
+}
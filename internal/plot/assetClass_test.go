@@ -0,0 +1,130 @@
+package plot
+
+import (
+	"InvestmentHelpver_V2/internal/alphavantage"
+
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResolveAssetClass(t *testing.T) {
+	tests := []struct {
+		symbol     string
+		assetClass AssetClass
+		want       AssetClass
+	}{
+		{"IBM", "", AssetClassEquity},
+		{"EUR/USD", "", AssetClassFX},
+		{"BTC/USD", AssetClassCrypto, AssetClassCrypto},
+		{"BTC/EUR", "", AssetClassCrypto},
+		{"eth/usd", "", AssetClassCrypto},
+		{"USD/JPY", "", AssetClassFX},
+		{"BTC/USD", AssetClassFX, AssetClassFX},
+		{"BTC", AssetClassCrypto, AssetClassCrypto},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test symbol:%s class:%q", test.symbol, test.assetClass), func(t *testing.T) {
+			if got := ResolveAssetClass(test.symbol, test.assetClass); got != test.want {
+				t.Errorf("wrong asset class, want %s, get %s", test.want, got)
+			}
+		})
+	}
+	if _, err := ParseAssetClass("bonds"); err == nil {
+		t.Error("expected wrong asset class error")
+	}
+}
+
+func TestCurrencyParams(t *testing.T) {
+	tests := []struct {
+		symbol  string
+		options PlotOptions
+		want    map[string]string
+		wantErr bool
+	}{
+		{"eur/usd", PlotOptions{}, map[string]string{"function": "FX_DAILY", "from_symbol": "EUR", "to_symbol": "USD"}, false},
+		{"EUR/USD", PlotOptions{Interval: Interval5Min}, map[string]string{"function": "FX_INTRADAY", "interval": "5min"}, false},
+		{"BTC", PlotOptions{Interval: IntervalWeekly, AssetClass: AssetClassCrypto},
+			map[string]string{"function": "DIGITAL_CURRENCY_WEEKLY", "symbol": "BTC", "market": "USD"}, false},
+		{"BTC/EUR", PlotOptions{AssetClass: AssetClassCrypto}, map[string]string{"function": "DIGITAL_CURRENCY_DAILY", "market": "EUR"}, false},
+		{"EUR", PlotOptions{AssetClass: AssetClassFX}, nil, true},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test symbol:%s", test.symbol), func(t *testing.T) {
+			params, err := PlotParams(test.symbol, test.options)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("wrong error %v", err)
+			}
+			for name, value := range test.want {
+				if params.Get(name) != value {
+					t.Errorf("wrong param %s, want %s, get %s", name, value, params.Get(name))
+				}
+			}
+		})
+	}
+}
+
+func TestScrapCurrencyJSONBody(t *testing.T) {
+	tests := []struct {
+		name       string
+		options    PlotOptions
+		body       string
+		wantVolume int
+	}{
+		{"fx", PlotOptions{Interval: IntervalDaily, AssetClass: AssetClassFX},
+			`{"Time Series FX (Daily)": {"2020-05-12": {"1. open": "1.08", "2. high": "1.09", "3. low": "1.07", "4. close": "1.085"}}}`, 0},
+		{"crypto", PlotOptions{Interval: IntervalDaily, AssetClass: AssetClassCrypto},
+			`{"Time Series (Digital Currency Daily)": {"2020-05-12": {"1. open": "1.08", "2. high": "1.09", "3. low": "1.07",
+			"4. close": "1.085", "5. volume": "10.6"}}}`, 11},
+		{"crypto old format", PlotOptions{Interval: IntervalWeekly, AssetClass: AssetClassCrypto},
+			`{"Time Series (Digital Currency Weekly)": {"2020-05-12": {"1a. open (EUR)": "1.08", "1b. open (USD)": "2",
+			"2a. high (EUR)": "1.09", "2b. high (USD)": "2", "3a. low (EUR)": "1.07", "3b. low (USD)": "2",
+			"4a. close (EUR)": "1.085", "4b. close (USD)": "2", "5. volume": "3", "6. market cap (USD)": "6"}}}`, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plot, err := ScrapCurrencyJSONBody(test.body, test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := Candle{Date: date(2020, time.May, 12), Open: 1.08, High: 1.09, Low: 1.07, Close: 1.085, Volume: test.wantVolume}
+			if len(plot) != 1 || plot[0] != want {
+				t.Errorf("wrong plot, want %+v, get %+v", want, plot)
+			}
+		})
+	}
+	_, err := ScrapCurrencyJSONBody(`{"Time Series FX (Daily)": {}}`, PlotOptions{Interval: IntervalWeekly, AssetClass: AssetClassFX})
+	if !errors.Is(err, alphavantage.ErrMalformedResponse) {
+		t.Errorf("wrong error %v", err)
+	}
+}
+
+func TestGetPlotAlphaVantageCurrency(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("function") != "FX_DAILY" || query.Get("from_symbol") != "EUR" || query.Get("to_symbol") != "USD" {
+			fmt.Fprint(w, `{"Error Message": "Invalid API call."}`)
+			return
+		}
+		fmt.Fprint(w, `{"Time Series FX (Daily)": {
+			"2020-05-12": {"1. open": "1.08", "2. high": "1.09", "3. low": "1.07", "4. close": "1.085"},
+			"2020-05-11": {"1. open": "1.09", "2. high": "1.10", "3. low": "1.08", "4. close": "1.08"}}}`)
+	}))
+	defer server.Close()
+	client := alphavantage.NewClient(server.URL, server.Client(), alphavantage.NewKeyPool("TESTKEY"), nil)
+	plotManagerTest := NewPlotManagerAlphaVantageWithClient(client)
+
+	plot, err := plotManagerTest.GetPlot("EUR/USD", PlotOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plot) != 2 || plot[1].Close != 1.085 {
+		t.Errorf("wrong plot %+v", plot)
+	}
+	if _, err := plotManagerTest.GetPlot("EUR/USD", PlotOptions{Adjusted: true}); err == nil {
+		t.Error("expected adjusted not supported error")
+	}
+}
@@ -0,0 +1,90 @@
+package plot
+
+import (
+	"sync"
+)
+
+// Структура ComparedSeries содержит график одного символа приведенный к общим датам и нормализованный к базе,
+// TotalReturn - доходность за период по ценам закрытия (0.1 - 10%)
+type ComparedSeries struct {
+	Symbol      string
+	Candles     []Candle
+	TotalReturn float64
+}
+
+// Метод получающий графики нескольких символов параллельно через PlotManager, возвращает графики в порядке символов,
+// если хотя бы один график получить не удалось возвращает ошибку первого по порядку символа
+func GetPlots(plotManager PlotManager, symbols []string, options PlotOptions) ([][]Candle, error) {
+	plots := make([][]Candle, len(symbols))
+	errs := make([]error, len(symbols))
+	var wg sync.WaitGroup
+	for i, symbol := range symbols {
+		wg.Add(1)
+		go func(i int, symbol string) {
+			defer wg.Done()
+			plots[i], errs[i] = plotManager.GetPlot(symbol, options)
+		}(i, symbol)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return plots, nil
+}
+
+// Метод оставляющий в каждом из отсортированных графиков только свечи с датами которые есть во всех графиках,
+// исходные графики не изменяются
+func AlignByDate(plots ...[]Candle) [][]Candle {
+	counts := map[int64]int{}
+	for _, candles := range plots {
+		for _, candle := range candles {
+			counts[candle.Date.UnixNano()]++
+		}
+	}
+	aligned := make([][]Candle, len(plots))
+	for i, candles := range plots {
+		aligned[i] = []Candle{}
+		for _, candle := range candles {
+			if counts[candle.Date.UnixNano()] == len(plots) {
+				aligned[i] = append(aligned[i], candle)
+			}
+		}
+	}
+	return aligned
+}
+
+// Метод нормализующий график так, что цена закрытия первой свечи равна base: цены Open, High, Low, Close и AdjustedClose
+// умножаются на base / Close первой свечи, объем и дивиденды не меняются. Исходный список не изменяется
+func Normalize(candles []Candle, base float64) []Candle {
+	normalized := make([]Candle, len(candles))
+	if len(candles) == 0 || candles[0].Close == 0 {
+		copy(normalized, candles)
+		return normalized
+	}
+	factor := base / candles[0].Close
+	for i, candle := range candles {
+		candle.Open *= factor
+		candle.High *= factor
+		candle.Low *= factor
+		candle.Close *= factor
+		candle.AdjustedClose *= factor
+		normalized[i] = candle
+	}
+	return normalized
+}
+
+// Метод сравнивающий графики символов: приводит их к общим датам, нормализует к base и считает доходность за период
+func Compare(symbols []string, plots [][]Candle, base float64) []ComparedSeries {
+	aligned := AlignByDate(plots...)
+	compared := make([]ComparedSeries, len(symbols))
+	for i, symbol := range symbols {
+		candles := aligned[i]
+		compared[i] = ComparedSeries{Symbol: symbol, Candles: Normalize(candles, base)}
+		if len(candles) > 0 && candles[0].Close != 0 {
+			compared[i].TotalReturn = candles[len(candles)-1].Close/candles[0].Close - 1
+		}
+	}
+	return compared
+}
@@ -0,0 +1,55 @@
+package plot
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestAlignByDate(t *testing.T) {
+	first := []Candle{{Date: date(2020, time.May, 11), Close: 1}, {Date: date(2020, time.May, 12), Close: 2}, {Date: date(2020, time.May, 13), Close: 3}}
+	second := []Candle{{Date: date(2020, time.May, 12), Close: 20}, {Date: date(2020, time.May, 13), Close: 30}, {Date: date(2020, time.May, 14), Close: 40}}
+	aligned := AlignByDate(first, second)
+	if len(aligned) != 2 || len(aligned[0]) != 2 || len(aligned[1]) != 2 {
+		t.Fatalf("wrong aligned %+v", aligned)
+	}
+	for i := range aligned[0] {
+		if !aligned[0][i].Date.Equal(aligned[1][i].Date) {
+			t.Errorf("dates are not aligned %s %s", aligned[0][i].Date, aligned[1][i].Date)
+		}
+	}
+	if len(first) != 3 {
+		t.Error("source plot must not change")
+	}
+	if aligned := AlignByDate(first, []Candle{}); len(aligned[0]) != 0 {
+		t.Errorf("expected empty plot, get %+v", aligned[0])
+	}
+}
+
+func TestCompare(t *testing.T) {
+	plots := [][]Candle{
+		{{Date: date(2020, time.May, 11), Close: 50, High: 60}, {Date: date(2020, time.May, 12), Close: 55}, {Date: date(2020, time.May, 13), Close: 60}},
+		{{Date: date(2020, time.May, 12), Close: 200}, {Date: date(2020, time.May, 13), Close: 150}},
+	}
+	compared := Compare([]string{"IBM", "MSFT"}, plots, 100)
+	if compared[0].Symbol != "IBM" || len(compared[0].Candles) != 2 || compared[0].Candles[0].Close != 100 ||
+		math.Abs(compared[0].Candles[1].Close-109.0909) > 1e-4 || math.Abs(compared[0].TotalReturn-0.090909) > 1e-6 {
+		t.Errorf("wrong series %+v", compared[0])
+	}
+	if compared[1].Candles[1].Close != 75 || compared[1].TotalReturn != -0.25 {
+		t.Errorf("wrong series %+v", compared[1])
+	}
+}
+
+func TestGetPlots(t *testing.T) {
+	plotManager := testPlotManager{plot: []Candle{{Date: date(2020, time.May, 11), Close: 1}}}
+	plots, err := GetPlots(plotManager, []string{"IBM", "MSFT"}, PlotOptions{})
+	if err != nil || len(plots) != 2 || len(plots[1]) != 1 {
+		t.Errorf("wrong plots %+v, error %v", plots, err)
+	}
+	brokenErr := errors.New("broken")
+	if _, err := GetPlots(testPlotManager{err: brokenErr}, []string{"IBM"}, PlotOptions{}); err != brokenErr {
+		t.Errorf("wrong error %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package plot
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Реализация интерфейса PlotManager, читает историю цен из каталога с CSV файлами (один файл на символ).
+// Поддерживаются выгрузки Yahoo (Date,Open,High,Low,Close,Adj Close,Volume) и Stooq (Date,Open,High,Low,Close,Volume
+// или <TICKER>,<PER>,<DATE>,<TIME>,<OPEN>,<HIGH>,<LOW>,<CLOSE>,<VOL>,<OPENINT>)
+type PlotManagerCSV struct {
+	Dir string // каталог с файлами <SYMBOL>.csv, <symbol>.csv или <symbol>.us.txt
+}
+
+// Конструктор для структуры PlotManagerCSV
+func NewPlotManagerCSV(dir string) PlotManager {
+	plotManager := PlotManagerCSV{dir}
+	return plotManager
+}
+
+// Метод структуры PlotManagerCSV, принимает символ финансового актива и параметры графика, возвращает список экземпляров структуры Candle.
+// Файлы содержат дневные свечи, недельные и месячные получаются агрегацией, внутридневные интервалы, валютные пары и криптовалюты не поддерживаются
+func (plotManager PlotManagerCSV) GetPlot(symbol string, options PlotOptions) ([]Candle, error) {
+	if options.Interval == "" {
+		options.Interval = IntervalDaily
+	}
+	if options.Interval.IsIntraday() {
+		return nil, errors.New("intervalNotSupported")
+	}
+	if ResolveAssetClass(symbol, options.AssetClass) != AssetClassEquity {
+		return nil, errors.New("assetClassNotSupported")
+	}
+	path, err := plotManager.findFile(symbol)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	plot, hasAdjusted, err := ScrapCSV(file)
+	if err != nil {
+		return nil, err
+	}
+	if options.Adjusted {
+		if !hasAdjusted {
+			return nil, errors.New("adjustedNotSupported")
+		}
+		plot = AdjustCandles(plot)
+	}
+	switch options.Interval {
+	case IntervalWeekly:
+		plot, err = Resample(plot, PeriodWeekly)
+	case IntervalMonthly:
+		plot, err = Resample(plot, PeriodMonthly)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return FilterCandles(plot, options.From, options.To), nil
+}
+
+// Вспомогательный метод ищущий файл символа в каталоге, символы с разделителями пути не принимаются
+func (plotManager PlotManagerCSV) findFile(symbol string) (string, error) {
+	if symbol == "" || strings.ContainsAny(symbol, `/\`) || strings.Contains(symbol, "..") {
+		return "", errors.New("wrongSymbol")
+	}
+	names := []string{symbol + ".csv", strings.ToUpper(symbol) + ".csv", strings.ToLower(symbol) + ".csv",
+		strings.ToLower(symbol) + ".us.txt"}
+	for _, name := range names {
+		path := filepath.Join(plotManager.Dir, name)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path, nil
+		}
+	}
+	return "", errors.New("symbolFileNotFound")
+}
+
+// Метод читающий CSV с заголовком и превращающий его в отсортированный по дате список структуры Candle,
+// дополнительно возвращает true если в файле есть скорректированная цена закрытия (Adj Close).
+// Строки с пустыми значениями (null у Yahoo) пропускаются
+func ScrapCSV(reader io.Reader) ([]Candle, bool, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, false, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[normalizeColumn(name)] = i
+	}
+	for _, required := range []string{"date", "open", "high", "low", "close"} {
+		if _, ok := columns[required]; !ok {
+			return nil, false, errors.New("missingColumn")
+		}
+	}
+	adjustedColumn, hasAdjusted := columns["adjclose"]
+	volumeColumn, hasVolume := columns["volume"]
+	if !hasVolume {
+		volumeColumn, hasVolume = columns["vol"]
+	}
+	days := []Candle{}
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if len(record) < len(header) || hasEmptyValue(record) {
+			continue
+		}
+		date, err := parseCSVDate(record[columns["date"]])
+		if err != nil {
+			return nil, false, err
+		}
+		prices, err := GetFloatPrices(record[columns["open"]], record[columns["high"]], record[columns["low"]], record[columns["close"]])
+		if err != nil {
+			return nil, false, err
+		}
+		day := Candle{Date: date, Open: prices[0], High: prices[1], Low: prices[2], Close: prices[3]}
+		if hasVolume {
+			volume, err := strconv.ParseFloat(record[volumeColumn], 64)
+			if err != nil {
+				return nil, false, err
+			}
+			day.Volume = int(volume)
+		}
+		if hasAdjusted {
+			day.AdjustedClose, err = strconv.ParseFloat(record[adjustedColumn], 64)
+			if err != nil {
+				return nil, false, err
+			}
+		}
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date.Before(days[j].Date) })
+	return days, hasAdjusted, nil
+}
+
+// Вспомогательный метод приводящий название колонки к виду без регистра, пробелов и угловых скобок (Adj Close -> adjclose, <VOL> -> vol)
+func normalizeColumn(name string) string {
+	name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	return strings.NewReplacer("<", "", ">", "", " ", "", "_", "").Replace(name)
+}
+
+// Вспомогательный метод возвращающий true если в строке есть пустое значение или null
+func hasEmptyValue(record []string) bool {
+	for _, value := range record {
+		if value == "" || value == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// Вспомогательный метод разбирающий дату в формате yyyy-mm-dd или yyyymmdd
+func parseCSVDate(value string) (time.Time, error) {
+	if len(value) == 8 {
+		return time.Parse("20060102", value)
+	}
+	return time.Parse("2006-01-02", value)
+}
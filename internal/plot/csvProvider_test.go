@@ -0,0 +1,96 @@
+package plot
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScrapCSV(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		wantLen      int
+		wantAdjusted bool
+	}{
+		{"yahoo", "Date,Open,High,Low,Close,Adj Close,Volume\n" +
+			"2020-05-13,2,3,1,2.5,2.4,200\n2020-05-12,1,2,0.5,1.5,1.4,100\n2020-05-14,null,null,null,null,null,null\n", 2, true},
+		{"stooq", "Date,Open,High,Low,Close,Volume\n2020-05-12,1,2,0.5,1.5,100.0\n", 1, false},
+		{"stooq old", "<TICKER>,<PER>,<DATE>,<TIME>,<OPEN>,<HIGH>,<LOW>,<CLOSE>,<VOL>,<OPENINT>\n" +
+			"IBM.US,D,20200512,000000,1,2,0.5,1.5,100,0\n", 1, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plot, hasAdjusted, err := ScrapCSV(strings.NewReader(test.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(plot) != test.wantLen || hasAdjusted != test.wantAdjusted {
+				t.Fatalf("wrong result, length %d, adjusted %v", len(plot), hasAdjusted)
+			}
+			first := plot[0]
+			if !first.Date.Equal(date(2020, time.May, 12)) || first.Close != 1.5 || first.Volume != 100 {
+				t.Errorf("wrong first candle %+v", first)
+			}
+		})
+	}
+
+	t.Run("missing column", func(t *testing.T) {
+		_, _, err := ScrapCSV(strings.NewReader("Date,Open,High,Low\n2020-05-12,1,2,0.5\n"))
+		if err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestGetPlotCSV(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plotcsv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	body := "Date,Open,High,Low,Close,Adj Close,Volume\n" +
+		"2020-05-11,1,2,1,2,1,100\n2020-05-12,2,3,1,2,1,100\n2020-05-18,2,5,2,4,2,100\n"
+	err = ioutil.WriteFile(filepath.Join(dir, "IBM.csv"), []byte(body), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plotManagerTest := NewPlotManagerCSV(dir)
+
+	t.Run("test daily", func(t *testing.T) {
+		plot, err := plotManagerTest.GetPlot("ibm", PlotOptions{From: date(2020, time.May, 12)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(plot) != 2 {
+			t.Errorf("wrong length, want 2, get %d", len(plot))
+		}
+	})
+
+	t.Run("test weekly adjusted", func(t *testing.T) {
+		plot, err := plotManagerTest.GetPlot("IBM", PlotOptions{Interval: IntervalWeekly, Adjusted: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(plot) != 2 || plot[0].Close != 1 || plot[0].High != 1.5 || plot[1].Close != 2 {
+			t.Errorf("wrong weekly candles %+v", plot)
+		}
+	})
+
+	t.Run("test intraday", func(t *testing.T) {
+		if _, err := plotManagerTest.GetPlot("IBM", PlotOptions{Interval: Interval5Min}); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("test unknown symbol", func(t *testing.T) {
+		for _, symbol := range []string{"unrealSymbol", "../IBM", ""} {
+			if _, err := plotManagerTest.GetPlot(symbol, PlotOptions{}); err == nil {
+				t.Errorf("expected error for %q", symbol)
+			}
+		}
+	})
+}
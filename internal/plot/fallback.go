@@ -0,0 +1,78 @@
+package plot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Структура Provider связывает реализацию PlotManager с названием поставщика данных
+type Provider struct {
+	Name    string      // название поставщика, например alphavantage
+	Manager PlotManager // реализация PlotManager этого поставщика
+}
+
+// интерфейс менеджера графиков который дополнительно сообщает название поставщика отдавшего данные
+type SourcedPlotManager interface {
+	PlotManager
+	GetPlotWithSource(string, PlotOptions) ([]Candle, string, error) // как GetPlot, дополнительно возвращает название поставщика
+}
+
+// Реализация интерфейса PlotManager, по очереди опрашивает поставщиков и возвращает данные первого ответившего без ошибки
+type PlotManagerFallback struct {
+	Providers []Provider // поставщики в порядке приоритета
+}
+
+// Конструктор для структуры PlotManagerFallback
+func NewPlotManagerFallback(providers ...Provider) SourcedPlotManager {
+	plotManager := PlotManagerFallback{providers}
+	return plotManager
+}
+
+// Метод структуры PlotManagerFallback, принимает символ финансового актива и параметры графика, возвращает список экземпляров структуры Candle
+func (plotManager PlotManagerFallback) GetPlot(symbol string, options PlotOptions) ([]Candle, error) {
+	plot, _, err := plotManager.GetPlotWithSource(symbol, options)
+	return plot, err
+}
+
+// Метод структуры PlotManagerFallback, принимает символ финансового актива и параметры графика,
+// возвращает список экземпляров структуры Candle и название поставщика который их отдал
+func (plotManager PlotManagerFallback) GetPlotWithSource(symbol string, options PlotOptions) ([]Candle, string, error) {
+	fallbackErr := &FallbackError{}
+	for _, provider := range plotManager.Providers {
+		plot, err := provider.Manager.GetPlot(symbol, options)
+		if err == nil {
+			return plot, provider.Name, nil
+		}
+		log.Printf("plot provider %s failed for %s: %v\n", provider.Name, symbol, err)
+		fallbackErr.Providers = append(fallbackErr.Providers, provider.Name)
+		fallbackErr.Errors = append(fallbackErr.Errors, err)
+	}
+	return nil, "", fallbackErr
+}
+
+// Структура FallbackError возвращается если ни один поставщик не отдал данные, содержит ошибки всех поставщиков
+type FallbackError struct {
+	Providers []string // названия опрошенных поставщиков
+	Errors    []error  // ошибки поставщиков в том же порядке
+}
+
+// Метод возвращающий текст ошибки со списком ошибок всех поставщиков
+func (fallbackErr *FallbackError) Error() string {
+	if len(fallbackErr.Errors) == 0 {
+		return "noPlotProviders"
+	}
+	messages := make([]string, len(fallbackErr.Errors))
+	for i, err := range fallbackErr.Errors {
+		messages[i] = fmt.Sprintf("%s: %v", fallbackErr.Providers[i], err)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Метод возвращающий ошибку основного (первого) поставщика, чтобы по ней можно было определить причину через errors.Is и errors.As
+func (fallbackErr *FallbackError) Unwrap() error {
+	if len(fallbackErr.Errors) == 0 {
+		return nil
+	}
+	return fallbackErr.Errors[0]
+}
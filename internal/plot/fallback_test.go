@@ -0,0 +1,64 @@
+package plot
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// Заглушка PlotManager возвращающая заранее заданный результат
+type testPlotManager struct {
+	plot  []Candle
+	err   error
+	calls *int
+}
+
+func (plotManager testPlotManager) GetPlot(symbol string, options PlotOptions) ([]Candle, error) {
+	if plotManager.calls != nil {
+		*plotManager.calls++
+	}
+	return plotManager.plot, plotManager.err
+}
+
+func TestPlotManagerFallback(t *testing.T) {
+	errPrimary := errors.New("primaryFailed")
+	candles := []Candle{{Date: date(2020, time.May, 12), Close: 1}}
+
+	t.Run("test first provider", func(t *testing.T) {
+		secondCalls := 0
+		plotManager := NewPlotManagerFallback(
+			Provider{"first", testPlotManager{plot: candles}},
+			Provider{"second", testPlotManager{plot: candles, calls: &secondCalls}},
+		)
+		plot, source, err := plotManager.GetPlotWithSource("IBM", PlotOptions{})
+		if err != nil || len(plot) != 1 || source != "first" || secondCalls != 0 {
+			t.Errorf("wrong result: source %s, err %v, second calls %d", source, err, secondCalls)
+		}
+	})
+
+	t.Run("test fallback provider", func(t *testing.T) {
+		plotManager := NewPlotManagerFallback(
+			Provider{"first", testPlotManager{err: errPrimary}},
+			Provider{"second", testPlotManager{plot: candles}},
+		)
+		plot, source, err := plotManager.GetPlotWithSource("IBM", PlotOptions{})
+		if err != nil || len(plot) != 1 || source != "second" {
+			t.Errorf("wrong result: source %s, err %v", source, err)
+		}
+	})
+
+	t.Run("test all providers failed", func(t *testing.T) {
+		plotManager := NewPlotManagerFallback(
+			Provider{"first", testPlotManager{err: errPrimary}},
+			Provider{"second", testPlotManager{err: errors.New("secondFailed")}},
+		)
+		_, err := plotManager.GetPlot("IBM", PlotOptions{})
+		var fallbackErr *FallbackError
+		if !errors.As(err, &fallbackErr) || len(fallbackErr.Errors) != 2 {
+			t.Fatalf("wrong error %v", err)
+		}
+		if !errors.Is(err, errPrimary) {
+			t.Error("error does not wrap primary provider error")
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package plottest
+
+import (
+	"InvestmentHelpver_V2/internal/plot"
+
+	"time"
+)
+
+// Дата первой свечи тестовых графиков, следующие свечи идут через день
+var Start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Метод создающий дневные свечи по ценам закрытия, цены открытия, максимума и минимума равны цене закрытия
+func Candles(prices ...float64) []plot.Candle {
+	candles := make([]plot.Candle, len(prices))
+	for i, price := range prices {
+		candles[i] = plot.Candle{Date: Start.AddDate(0, 0, i), Open: price, High: price, Low: price, Close: price, Volume: 100}
+	}
+	return candles
+}
+
+// Метод создающий дневные свечи из цен open, high, low, close
+func OHLC(prices ...[4]float64) []plot.Candle {
+	candles := make([]plot.Candle, len(prices))
+	for i, price := range prices {
+		candles[i] = plot.Candle{Date: Start.AddDate(0, 0, i), Open: price[0], High: price[1], Low: price[2], Close: price[3], Volume: 100}
+	}
+	return candles
+}
@@ -0,0 +1,132 @@
+package plot
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Период агрегации свечей: weekly, monthly, quarterly или <N>d - N торговых дней (например 5d)
+type Period string
+
+const (
+	PeriodWeekly    Period = "weekly"
+	PeriodMonthly   Period = "monthly"
+	PeriodQuarterly Period = "quarterly"
+)
+
+// Метод превращающий строку из запроса в Period
+func ParsePeriod(s string) (Period, error) {
+	period := Period(strings.ToLower(s))
+	switch period {
+	case PeriodWeekly, PeriodMonthly, PeriodQuarterly:
+		return period, nil
+	}
+	if _, ok := period.tradingDays(); ok {
+		return period, nil
+	}
+	return "", errors.New("wrongPeriod")
+}
+
+// Вспомогательный метод возвращающий количество торговых дней для периода вида <N>d
+func (period Period) tradingDays() (int, bool) {
+	if !strings.HasSuffix(string(period), "d") {
+		return 0, false
+	}
+	days, err := strconv.Atoi(strings.TrimSuffix(string(period), "d"))
+	if err != nil || days <= 0 {
+		return 0, false
+	}
+	return days, true
+}
+
+// Метод агрегирующий отсортированный список свечей в более крупные свечи указанного периода.
+// Open берется у первой свечи группы, Close у последней, High и Low - экстремумы группы, Volume - сумма,
+// датой свечи становится дата последней свечи группы (как в недельных и месячных рядах Alpha Vantage),
+// у скорректированных рядов дивиденды группы суммируются, а коэффициенты сплитов перемножаются.
+// Недели, месяцы и кварталы определяются по календарю, периоды <N>d отсчитываются в торговых сессиях NYSE
+// от первой свечи, поэтому пропуски в данных не сдвигают границы групп
+func Resample(candles []Candle, period Period) ([]Candle, error) {
+	key, err := period.bucketKey(candles)
+	if err != nil {
+		return nil, err
+	}
+	resampled := []Candle{}
+	lastKey := -1
+	for i, candle := range candles {
+		bucket := key(i)
+		if len(resampled) == 0 || bucket != lastKey {
+			resampled = append(resampled, candle)
+			lastKey = bucket
+			continue
+		}
+		current := &resampled[len(resampled)-1]
+		current.Date = candle.Date
+		current.High = math.Max(current.High, candle.High)
+		current.Low = math.Min(current.Low, candle.Low)
+		current.Close = candle.Close
+		current.Volume += candle.Volume
+		current.AdjustedClose = candle.AdjustedClose
+		current.DividendAmount += candle.DividendAmount
+		if candle.SplitCoefficient != 0 {
+			current.SplitCoefficient = math.Max(current.SplitCoefficient, 1) * candle.SplitCoefficient
+		}
+	}
+	return resampled, nil
+}
+
+// Вспомогательный метод возвращающий функцию которая по индексу свечи возвращает номер ее группы
+func (period Period) bucketKey(candles []Candle) (func(int) int, error) {
+	switch period {
+	case PeriodWeekly:
+		return func(i int) int {
+			year, week := candles[i].Date.ISOWeek()
+			return year*100 + week
+		}, nil
+	case PeriodMonthly:
+		return func(i int) int {
+			return candles[i].Date.Year()*12 + int(candles[i].Date.Month())
+		}, nil
+	case PeriodQuarterly:
+		return func(i int) int {
+			return candles[i].Date.Year()*4 + (int(candles[i].Date.Month())-1)/3
+		}, nil
+	}
+	days, ok := period.tradingDays()
+	if !ok {
+		return nil, errors.New("wrongPeriod")
+	}
+	sessions := sessionIndexes(candles)
+	return func(i int) int {
+		return sessions[i] / days
+	}, nil
+}
+
+// Вспомогательный метод возвращающий для каждой свечи номер торговой сессии считая от даты первой свечи,
+// внутридневные свечи одного дня получают один номер
+func sessionIndexes(candles []Candle) []int {
+	indexes := make([]int, len(candles))
+	if len(candles) == 0 {
+		return indexes
+	}
+	session := 0
+	day := TruncateDay(candles[0].Date)
+	for i, candle := range candles {
+		candleDay := TruncateDay(candle.Date)
+		for day.Before(candleDay) {
+			day = day.AddDate(0, 0, 1)
+			if IsTradingDay(day) {
+				session++
+			}
+		}
+		indexes[i] = session
+	}
+	return indexes
+}
+
+// Метод отбрасывающий время у даты, возвращает полночь того же календарного дня в UTC
+func TruncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,91 @@
+package plot
+
+import (
+	"testing"
+	"time"
+)
+
+// Вспомогательный метод создающий дневную свечу
+func testCandle(day time.Time, open, high, low, close float64, volume int) Candle {
+	return Candle{Date: day, Open: open, High: high, Low: low, Close: close, Volume: volume}
+}
+
+func TestParsePeriod(t *testing.T) {
+	for _, input := range []string{"weekly", "Monthly", "quarterly", "5d", "1d"} {
+		if _, err := ParsePeriod(input); err != nil {
+			t.Errorf("unexpected error for %s: %v", input, err)
+		}
+	}
+	for _, input := range []string{"", "0d", "-2d", "yearly", "d"} {
+		if _, err := ParsePeriod(input); err == nil {
+			t.Errorf("expected error for %s", input)
+		}
+	}
+}
+
+func TestResample(t *testing.T) {
+	// неделя 6-10 апреля 2020 года с выходным в Страстную пятницу и следующая неделя
+	candles := []Candle{
+		testCandle(date(2020, time.April, 6), 10, 12, 9, 11, 100),
+		testCandle(date(2020, time.April, 7), 11, 15, 10, 14, 200),
+		testCandle(date(2020, time.April, 9), 14, 14, 8, 9, 300),
+		testCandle(date(2020, time.April, 13), 9, 10, 7, 8, 400),
+		testCandle(date(2020, time.April, 14), 8, 9, 6, 7, 500),
+		testCandle(date(2020, time.May, 1), 7, 20, 7, 19, 600),
+	}
+	tests := []struct {
+		period Period
+		want   []Candle
+	}{
+		{PeriodWeekly, []Candle{
+			testCandle(date(2020, time.April, 9), 10, 15, 8, 9, 600),
+			testCandle(date(2020, time.April, 14), 9, 10, 6, 7, 900),
+			testCandle(date(2020, time.May, 1), 7, 20, 7, 19, 600),
+		}},
+		{PeriodMonthly, []Candle{
+			testCandle(date(2020, time.April, 14), 10, 15, 6, 7, 1500),
+			testCandle(date(2020, time.May, 1), 7, 20, 7, 19, 600),
+		}},
+		{PeriodQuarterly, []Candle{
+			testCandle(date(2020, time.May, 1), 10, 20, 6, 19, 2100),
+		}},
+		// 6, 7, 8 апреля - первая группа (8 апреля данных нет), 9 и 13 апреля - вторая, 10 апреля выходной
+		{"3d", []Candle{
+			testCandle(date(2020, time.April, 7), 10, 15, 9, 14, 300),
+			testCandle(date(2020, time.April, 14), 14, 14, 6, 7, 1200),
+			testCandle(date(2020, time.May, 1), 7, 20, 7, 19, 600),
+		}},
+	}
+	for _, test := range tests {
+		t.Run(string(test.period), func(t *testing.T) {
+			resampled, err := Resample(candles, test.period)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resampled) != len(test.want) {
+				t.Fatalf("wrong length, want %d, get %d: %+v", len(test.want), len(resampled), resampled)
+			}
+			for i := range test.want {
+				if resampled[i] != test.want[i] {
+					t.Errorf("wrong candle %d, want %+v, get %+v", i, test.want[i], resampled[i])
+				}
+			}
+		})
+	}
+}
+
+func TestResampleIntraday(t *testing.T) {
+	day := date(2020, time.May, 12)
+	candles := []Candle{
+		testCandle(day.Add(10*time.Hour), 1, 2, 1, 2, 10),
+		testCandle(day.Add(11*time.Hour), 2, 5, 2, 4, 10),
+		testCandle(day.AddDate(0, 0, 1).Add(10*time.Hour), 4, 4, 3, 3, 10),
+	}
+	resampled, err := Resample(candles, "1d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resampled) != 2 || resampled[0].High != 5 || resampled[0].Close != 4 || resampled[0].Volume != 20 {
+		t.Errorf("wrong daily candles %+v", resampled)
+	}
+}
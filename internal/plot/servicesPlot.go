@@ -1,11 +1,13 @@
 package plot
 
 import (
+	"InvestmentHelpver_V2/internal/alphavantage"
+	"InvestmentHelpver_V2/internal/httpclient"
+
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
@@ -13,104 +15,321 @@ import (
 )
 
 // Структура Candle (японская свеча) содержит дату, объем торгов в момент этой даты, а также информацию о цене в этот момент
+// (поля AdjustedClose, DividendAmount и SplitCoefficient заполняются только для скорректированных рядов)
 type Candle struct {
-	Date   time.Time // время в момент которого сущестует свеча, формат yyyy-mm-dd, время по ETS
-	Open   float64   // цена открытия
-	High   float64   // наивысшая цена
-	Low    float64   // наименьшая цена
-	Close  float64   // цена закрытия
-	Volume int       // объем торгов
+	Date             time.Time // время в момент которого сущестует свеча, формат yyyy-mm-dd (yyyy-mm-dd hh:mm:ss для внутридневных свечей), время по ETS
+	Open             float64   // цена открытия
+	High             float64   // наивысшая цена
+	Low              float64   // наименьшая цена
+	Close            float64   // цена закрытия
+	Volume           int       // объем торгов
+	AdjustedClose    float64   `json:",omitempty"` // цена закрытия скорректированная на сплиты и дивиденды
+	DividendAmount   float64   `json:",omitempty"` // размер дивиденда с отсечкой в эту дату
+	SplitCoefficient float64   `json:",omitempty"` // коэффициент сплита в эту дату (1 - сплита не было)
+}
+
+// Интервал свечей графика (1min, 5min, 15min, 30min, 60min - внутридневные, daily, weekly, monthly)
+type Interval string
+
+const (
+	Interval1Min    Interval = "1min"
+	Interval5Min    Interval = "5min"
+	Interval15Min   Interval = "15min"
+	Interval30Min   Interval = "30min"
+	Interval60Min   Interval = "60min"
+	IntervalDaily   Interval = "daily"
+	IntervalWeekly  Interval = "weekly"
+	IntervalMonthly Interval = "monthly"
+)
+
+// Метод превращающий строку из запроса в Interval, пустая строка означает дневной интервал
+func ParseInterval(s string) (Interval, error) {
+	if s == "" {
+		return IntervalDaily, nil
+	}
+	interval := Interval(strings.ToLower(s))
+	switch interval {
+	case Interval1Min, Interval5Min, Interval15Min, Interval30Min, Interval60Min,
+		IntervalDaily, IntervalWeekly, IntervalMonthly:
+		return interval, nil
+	}
+	return "", errors.New("wrongInterval")
+}
+
+// Метод возвращающий true для внутридневных интервалов
+func (interval Interval) IsIntraday() bool {
+	switch interval {
+	case Interval1Min, Interval5Min, Interval15Min, Interval30Min, Interval60Min:
+		return true
+	}
+	return false
+}
+
+// Вспомогательный метод возвращающий название функции Alpha Vantage для интервала и типа ряда (обычный или скорректированный)
+func (interval Interval) seriesFunction(adjusted bool) string {
+	function := "TIME_SERIES_DAILY"
+	switch {
+	case interval.IsIntraday():
+		return "TIME_SERIES_INTRADAY"
+	case interval == IntervalWeekly:
+		function = "TIME_SERIES_WEEKLY"
+	case interval == IntervalMonthly:
+		function = "TIME_SERIES_MONTHLY"
+	}
+	if adjusted {
+		function += "_ADJUSTED"
+	}
+	return function
+}
+
+// Вспомогательный метод возвращающий формат даты в ответе Alpha Vantage (у внутридневных свечей есть время)
+func (interval Interval) dateLayout() string {
+	if interval.IsIntraday() {
+		return "2006-01-02 15:04:05"
+	}
+	return "2006-01-02"
+}
+
+// Параметры запроса графика
+type PlotOptions struct {
+	Interval   Interval   // интервал свечей, пустое значение означает дневной интервал
+	From       time.Time  // начало периода включительно, нулевое значение - без ограничения
+	To         time.Time  // конец периода включительно, нулевое значение - без ограничения
+	Adjusted   bool       // true - цены скорректированы назад на сплиты и дивиденды (только для акций и интервалов daily, weekly и monthly)
+	AssetClass AssetClass // класс актива, пустое значение - определяется по символу (EUR/USD - валютная пара, иначе акции)
+}
+
+// Количество свечей которое Alpha Vantage отдает при outputsize=compact
+const compactOutputSize = 100
+
+// Метод возвращающий true если начало запрошенного периода раньше чем начало компактного ответа Alpha Vantage
+// и значит нужно запрашивать outputsize=full (недельные и месячные ряды всегда отдаются полностью)
+func (options PlotOptions) NeedFullOutput(now time.Time) bool {
+	if options.From.IsZero() || options.Interval == IntervalWeekly || options.Interval == IntervalMonthly {
+		return false
+	}
+	return options.From.Before(compactWindowStart(options.Interval, options.AssetClass, now))
 }
 
-// интерфейс менеджера графиков, реализующие его струтуры должны иметь метод получающий символ финансового актива
+// Вспомогательный метод оценивающий дату первой свечи в компактном ответе Alpha Vantage,
+// отсчитывает назад нужное количество торговых сессий NYSE (для валютных пар - будних дней, для криптовалют - всех дней)
+func compactWindowStart(interval Interval, assetClass AssetClass, now time.Time) time.Time {
+	sessionMinutes := 390 // торговая сессия NYSE длится 6.5 часов
+	isSession := IsTradingDay
+	switch assetClass {
+	case AssetClassFX:
+		sessionMinutes = 24 * 60
+		isSession = func(day time.Time) bool { return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday }
+	case AssetClassCrypto:
+		sessionMinutes = 24 * 60
+		isSession = func(time.Time) bool { return true }
+	}
+	sessions := compactOutputSize
+	if interval.IsIntraday() {
+		minutes, _ := strconv.Atoi(strings.TrimSuffix(string(interval), "min"))
+		candlesPerSession := sessionMinutes / minutes
+		sessions = (compactOutputSize + candlesPerSession - 1) / candlesPerSession
+	}
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for sessions > 0 {
+		start = start.AddDate(0, 0, -1)
+		if isSession(start) {
+			sessions--
+		}
+	}
+	return start
+}
+
+// Метод оставляющий в отсортированном списке свечей только свечи попадающие в период [from, to],
+// нулевые значения from и to означают отсутствие ограничения
+func FilterCandles(candles []Candle, from, to time.Time) []Candle {
+	start := 0
+	if !from.IsZero() {
+		start = sort.Search(len(candles), func(i int) bool { return !candles[i].Date.Before(from) })
+	}
+	end := len(candles)
+	if !to.IsZero() {
+		end = sort.Search(len(candles), func(i int) bool { return candles[i].Date.After(to) })
+	}
+	if start >= end {
+		return []Candle{}
+	}
+	return candles[start:end]
+}
+
+// интерфейс менеджера графиков, реализующие его струтуры должны иметь метод получающий символ финансового актива и параметры графика
 // и возвращать список свечей в виде списка экземпляров структуры Candle
 // (Tesla - название компании, TSLA - символ акций (финансового актива) этой компании на рынке)
 type PlotManager interface {
-	GetPlot(string) ([]Candle, error) // принимает символ финансового актива, возвращать список свечей в виде списка экземпляров структуры Candle
+	GetPlot(string, PlotOptions) ([]Candle, error) // принимает символ финансового актива и параметры, возвращать список свечей в виде списка экземпляров структуры Candle
 }
 
-// Реализация интерфейса PlotManager, имеет параметр apiKey являющийся клюом к API Alpha Ventage
+// Реализация интерфейса PlotManager, получает графики с Alpha Ventage через клиент alphavantage.Client,
+// который использует ключи из пула по кругу и общий для всех запросов с этими ключами ограничитель частоты запросов
 type PlotManagerAlphaVantage struct {
-	APIKey string
+	Client *alphavantage.Client
+}
+
+// Конструктор для структуры PlotManagerAlphaVantage, принимает один или несколько ключей,
+// использует адрес и HTTP клиент по умолчанию и общий ограничитель alphavantage.DefaultRateLimiter
+func NewPlotManagerAlphaVantage(apiKeys ...string) PlotManager {
+	client := alphavantage.NewClient(alphavantage.DefaultBaseURL, httpclient.Default, alphavantage.NewKeyPool(apiKeys...), alphavantage.DefaultRateLimiter)
+	return NewPlotManagerAlphaVantageWithClient(client)
 }
 
-// Конструктор для структуры PlotManagerAlphaVantage
-func NewPlotManagerAlphaVantage(apiKey string) PlotManager {
-	plotManager := PlotManagerAlphaVantage{apiKey}
+// Конструктор для структуры PlotManagerAlphaVantage, принимает настроенный клиент Alpha Vantage
+// (адрес API, HTTP клиент с таймаутами, пул ключей и ограничитель)
+func NewPlotManagerAlphaVantageWithClient(client *alphavantage.Client) PlotManager {
+	plotManager := PlotManagerAlphaVantage{client}
 	return plotManager
 }
 
-// Метод структуры PlotManagerAlphaVantage, принимает символ финансового актива, возвращает список экземпляров структуры Candle
-func (plotManager PlotManagerAlphaVantage) GetPlot(symbol string) ([]Candle, error) {
-	apiKey := plotManager.APIKey
-	body, err := GetPlotJSON(symbol, apiKey)
+// Метод структуры PlotManagerAlphaVantage, принимает символ финансового актива и параметры графика, возвращает список экземпляров структуры Candle
+// (валютные пары и криптовалюты задаются символом вида EUR/USD или BTC/USD и классом актива в options.AssetClass)
+func (plotManager PlotManagerAlphaVantage) GetPlot(symbol string, options PlotOptions) ([]Candle, error) {
+	if options.Interval == "" {
+		options.Interval = IntervalDaily
+	}
+	options.AssetClass = ResolveAssetClass(symbol, options.AssetClass)
+	if options.Adjusted && options.Interval.IsIntraday() {
+		return nil, errors.New("adjustedIntraday")
+	}
+	if options.Adjusted && options.AssetClass != AssetClassEquity {
+		return nil, errors.New("adjustedNotSupported")
+	}
+	body, err := plotManager.GetPlotJSON(symbol, options)
 	if err != nil {
 		return nil, err
 	}
-	plot, err := ScrapJSONBody(body)
+	var plot []Candle
+	if options.AssetClass == AssetClassEquity {
+		plot, err = ScrapJSONBody(body, options)
+	} else {
+		plot, err = ScrapCurrencyJSONBody(body, options)
+	}
 	if err != nil {
 		return nil, err
 	}
-	return plot, nil
+	if options.Adjusted {
+		plot = AdjustCandles(plot)
+	}
+	return FilterCandles(plot, options.From, options.To), nil
 }
 
-// Метод принимающий символ финансового актива и ключ API, производит запроса на Alpha Ventage и возвращает тело ответа
-func GetPlotJSON(symbol, apiKey string) (string, error) {
-	req := fmt.Sprintf("https://www.alphavantage.co/query?function=TIME_SERIES_DAILY&symbol=%s&apikey=%s", symbol, apiKey)
-	resp, err := http.Get(req)
+// Метод принимающий символ финансового актива и параметры графика, производит запроса на Alpha Ventage и возвращает тело ответа,
+// сообщения Alpha Vantage об ошибках превращаются в ошибки alphavantage.ErrRateLimited, ErrUnknownSymbol, ErrInvalidKey или ErrMalformedResponse
+func (plotManager PlotManagerAlphaVantage) GetPlotJSON(symbol string, options PlotOptions) (string, error) {
+	params, err := PlotParams(symbol, options)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := plotManager.Client.Query(params)
 	if err != nil {
 		return "", err
 	}
+	return string(body), nil
+}
 
-	if strings.Contains(string(body), "Note") {
-		err = errors.New("exceedApiFrequency")
-		return "", err
+// Метод возвращающий параметры запроса временного ряда Alpha Vantage для символа и параметров графика,
+// для валютных пар и криптовалют символ должен быть вида EUR/USD или BTC/USD
+func PlotParams(symbol string, options PlotOptions) (url.Values, error) {
+	interval := options.Interval
+	if interval == "" {
+		interval = IntervalDaily
+	}
+	options.Interval = interval
+	options.AssetClass = ResolveAssetClass(symbol, options.AssetClass)
+	if options.AssetClass != AssetClassEquity {
+		return currencyParams(symbol, options)
+	}
+	params := url.Values{}
+	params.Set("function", interval.seriesFunction(options.Adjusted))
+	params.Set("symbol", symbol)
+	if interval.IsIntraday() {
+		params.Set("interval", string(interval))
 	}
+	if options.NeedFullOutput(time.Now()) {
+		params.Set("outputsize", "full")
+	}
+	return params, nil
+}
 
-	if strings.Contains(string(body), "Invalid API call") {
-		err = errors.New("wrongSymbolApiCall")
-		return "", err
+// Структура timeSeriesResponse - ответ функций TIME_SERIES_* Alpha Vantage, каждому интервалу и типу ряда соответствует свой ключ
+type timeSeriesResponse struct {
+	Intraday1Min    map[string]timeSeriesCandle `json:"Time Series (1min)"`
+	Intraday5Min    map[string]timeSeriesCandle `json:"Time Series (5min)"`
+	Intraday15Min   map[string]timeSeriesCandle `json:"Time Series (15min)"`
+	Intraday30Min   map[string]timeSeriesCandle `json:"Time Series (30min)"`
+	Intraday60Min   map[string]timeSeriesCandle `json:"Time Series (60min)"`
+	Daily           map[string]timeSeriesCandle `json:"Time Series (Daily)"` // TIME_SERIES_DAILY и TIME_SERIES_DAILY_ADJUSTED
+	Weekly          map[string]timeSeriesCandle `json:"Weekly Time Series"`
+	WeeklyAdjusted  map[string]timeSeriesCandle `json:"Weekly Adjusted Time Series"`
+	Monthly         map[string]timeSeriesCandle `json:"Monthly Time Series"`
+	MonthlyAdjusted map[string]timeSeriesCandle `json:"Monthly Adjusted Time Series"`
+}
+
+// Структура timeSeriesCandle - свеча в ответе Alpha Vantage, у скорректированных рядов объем находится под ключом 6. volume
+type timeSeriesCandle struct {
+	Open             string `json:"1. open"`
+	High             string `json:"2. high"`
+	Low              string `json:"3. low"`
+	Close            string `json:"4. close"`
+	Volume           string `json:"5. volume"`
+	AdjustedClose    string `json:"5. adjusted close"`
+	AdjustedVolume   string `json:"6. volume"`
+	DividendAmount   string `json:"7. dividend amount"`
+	SplitCoefficient string `json:"8. split coefficient"`
+}
+
+// Вспомогательный метод возвращающий временной ряд соответствующий интервалу и типу ряда
+func (response timeSeriesResponse) series(interval Interval, adjusted bool) map[string]timeSeriesCandle {
+	switch interval {
+	case Interval1Min:
+		return response.Intraday1Min
+	case Interval5Min:
+		return response.Intraday5Min
+	case Interval15Min:
+		return response.Intraday15Min
+	case Interval30Min:
+		return response.Intraday30Min
+	case Interval60Min:
+		return response.Intraday60Min
+	case IntervalWeekly:
+		if adjusted {
+			return response.WeeklyAdjusted
+		}
+		return response.Weekly
+	case IntervalMonthly:
+		if adjusted {
+			return response.MonthlyAdjusted
+		}
+		return response.Monthly
 	}
-	return string(body), err
+	return response.Daily
 }
 
-// Метод принимающий в себя тело ответа из функции GetPlotJSON и превращающий его в список структуры Candle
-func ScrapJSONBody(body string) ([]Candle, error) {
-	byt := []byte(body)
-	var days []Candle
-	var dat map[string]interface{}
-	err := json.Unmarshal(byt, &dat)
+// Метод принимающий в себя тело ответа из метода GetPlotJSON и параметры запроса, превращающий его в список структуры Candle,
+// если ответ не удалось разобрать возвращает ошибку оборачивающую alphavantage.ErrMalformedResponse
+func ScrapJSONBody(body string, options PlotOptions) ([]Candle, error) {
+	interval := options.Interval
+	var response timeSeriesResponse
+	err := json.Unmarshal([]byte(body), &response)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
 	}
-	dailyTimeSeries := dat["Time Series (Daily)"].(map[string]interface{})
-	for dateKey := range dailyTimeSeries {
-		date, err := time.Parse("2006-01-02", dateKey)
-		if err != nil {
-			return nil, err
-		}
-		dayValues := dailyTimeSeries[dateKey].(map[string]interface{})
-		value, err := strconv.Atoi(dayValues["5. volume"].(string))
+	timeSeries := response.series(interval, options.Adjusted)
+	if timeSeries == nil {
+		return nil, fmt.Errorf("%w: no %s time series", alphavantage.ErrMalformedResponse, interval)
+	}
+	days := make([]Candle, 0, len(timeSeries))
+	for dateKey, values := range timeSeries {
+		date, err := time.Parse(interval.dateLayout(), dateKey)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
 		}
-		prices, err := GetFloatPrices(dayValues["1. open"].(string), dayValues["2. high"].(string),
-			dayValues["3. low"].(string), dayValues["4. close"].(string))
+		day, err := values.candle(date, options.Adjusted)
 		if err != nil {
-			return nil, err
-		}
-		day := Candle{
-			Date:   date,
-			Open:   prices[0],
-			High:   prices[1],
-			Low:    prices[2],
-			Close:  prices[3],
-			Volume: value,
+			return nil, fmt.Errorf("%w: %s: %v", alphavantage.ErrMalformedResponse, dateKey, err)
 		}
 		days = append(days, day)
 	}
@@ -118,6 +337,51 @@ func ScrapJSONBody(body string) ([]Candle, error) {
 	return days, nil
 }
 
+// Вспомогательный метод превращающий свечу из ответа Alpha Vantage в Candle,
+// у скорректированных рядов заполняет цену закрытия с поправками, дивиденд и коэффициент сплита
+// (в недельных и месячных рядах коэффициента сплита нет, он считается равным 1)
+func (values timeSeriesCandle) candle(date time.Time, adjusted bool) (Candle, error) {
+	prices, err := GetFloatPrices(values.Open, values.High, values.Low, values.Close)
+	if err != nil {
+		return Candle{}, err
+	}
+	volumeS := values.Volume
+	if adjusted {
+		volumeS = values.AdjustedVolume
+	}
+	volume, err := strconv.Atoi(volumeS)
+	if err != nil {
+		return Candle{}, err
+	}
+	day := Candle{
+		Date:   date,
+		Open:   prices[0],
+		High:   prices[1],
+		Low:    prices[2],
+		Close:  prices[3],
+		Volume: volume,
+	}
+	if !adjusted {
+		return day, nil
+	}
+	day.AdjustedClose, err = strconv.ParseFloat(values.AdjustedClose, 64)
+	if err != nil {
+		return Candle{}, err
+	}
+	day.DividendAmount, err = strconv.ParseFloat(values.DividendAmount, 64)
+	if err != nil {
+		return Candle{}, err
+	}
+	day.SplitCoefficient = 1
+	if values.SplitCoefficient != "" {
+		day.SplitCoefficient, err = strconv.ParseFloat(values.SplitCoefficient, 64)
+		if err != nil {
+			return Candle{}, err
+		}
+	}
+	return day, nil
+}
+
 // Вспомогательный метод превращающий цены в формате String в цены в формате Float64
 func GetFloatPrices(openS, highS, lowS, closeS string) ([]float64, error) {
 	openF, err := strconv.ParseFloat(openS, 64)
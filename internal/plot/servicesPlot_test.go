@@ -1,8 +1,14 @@
 package plot
 
 import (
+	"InvestmentHelpver_V2/internal/alphavantage"
+
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestGetPlotAlphaVantage(t *testing.T) {
@@ -12,7 +18,7 @@ func TestGetPlotAlphaVantage(t *testing.T) {
 	plotManagerTest := NewPlotManagerAlphaVantage(apiKey)
 
 	t.Run(fmt.Sprintf("test real stock symbol:%s", testSymbolReal), func(t *testing.T) {
-		plot, err := plotManagerTest.GetPlot(testSymbolReal)
+		plot, err := plotManagerTest.GetPlot(testSymbolReal, PlotOptions{})
 		if err != nil {
 			t.Error(err)
 		}
@@ -22,7 +28,7 @@ func TestGetPlotAlphaVantage(t *testing.T) {
 	})
 
 	t.Run(fmt.Sprintf("test unreal stock symbol:%s", testSymbolUnreal), func(t *testing.T) {
-		plot, err := plotManagerTest.GetPlot(testSymbolUnreal)
+		plot, err := plotManagerTest.GetPlot(testSymbolUnreal, PlotOptions{})
 		if err == nil {
 			t.Error(err)
 		}
@@ -31,3 +37,201 @@ func TestGetPlotAlphaVantage(t *testing.T) {
 		}
 	})
 }
+
+func TestGetPlotAlphaVantageLocalServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("function") != "TIME_SERIES_DAILY" || query.Get("apikey") != "TESTKEY" {
+			fmt.Fprint(w, `{"Error Message": "Invalid API call."}`)
+			return
+		}
+		fmt.Fprint(w, `{"Time Series (Daily)": {
+			"2020-05-12": {"1. open": "1.0", "2. high": "3.0", "3. low": "0.5", "4. close": "2.0", "5. volume": "100"},
+			"2020-05-11": {"1. open": "1.5", "2. high": "2.0", "3. low": "1.0", "4. close": "1.0", "5. volume": "200"}}}`)
+	}))
+	defer server.Close()
+	client := alphavantage.NewClient(server.URL, server.Client(), alphavantage.NewKeyPool("TESTKEY"), nil)
+	plotManagerTest := NewPlotManagerAlphaVantageWithClient(client)
+
+	t.Run("test plot from local server", func(t *testing.T) {
+		plot, err := plotManagerTest.GetPlot("IBM", PlotOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(plot) != 2 || plot[0].Close != 1 || plot[1].Close != 2 {
+			t.Errorf("wrong plot %+v", plot)
+		}
+	})
+
+	t.Run("test unknown symbol from local server", func(t *testing.T) {
+		_, err := plotManagerTest.GetPlot("IBM", PlotOptions{Interval: IntervalWeekly})
+		if !errors.Is(err, alphavantage.ErrUnknownSymbol) {
+			t.Errorf("wrong error %v", err)
+		}
+	})
+}
+
+func TestPlotParams(t *testing.T) {
+	params, err := PlotParams("IBM", PlotOptions{Interval: Interval5Min})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Get("function") != "TIME_SERIES_INTRADAY" || params.Get("interval") != "5min" || params.Get("symbol") != "IBM" {
+		t.Errorf("wrong params %v", params)
+	}
+	if params.Get("apikey") != "" {
+		t.Error("params must not contain api key")
+	}
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Interval
+		wantErr bool
+	}{
+		{"", IntervalDaily, false},
+		{"60min", Interval60Min, false},
+		{"Weekly", IntervalWeekly, false},
+		{"monthly", IntervalMonthly, false},
+		{"2min", "", true},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test interval:%q", test.input), func(t *testing.T) {
+			interval, err := ParseInterval(test.input)
+			if (err != nil) != test.wantErr {
+				t.Errorf("wrong error, get %v", err)
+			}
+			if interval != test.want {
+				t.Errorf("wrong interval, want %s, get %s", test.want, interval)
+			}
+		})
+	}
+}
+
+func TestScrapJSONBodyIntervals(t *testing.T) {
+	candle := `{"1. open": "1.0", "2. high": "3.0", "3. low": "0.5", "4. close": "2.0", "5. volume": "100"}`
+	tests := []struct {
+		interval Interval
+		body     string
+		want     []time.Time
+	}{
+		{Interval60Min, `{"Time Series (60min)": {"2020-05-12 11:00:00": ` + candle + `, "2020-05-12 10:00:00": ` + candle + `}}`,
+			[]time.Time{time.Date(2020, 5, 12, 10, 0, 0, 0, time.UTC), time.Date(2020, 5, 12, 11, 0, 0, 0, time.UTC)}},
+		{IntervalDaily, `{"Time Series (Daily)": {"2020-05-12": ` + candle + `}}`,
+			[]time.Time{time.Date(2020, 5, 12, 0, 0, 0, 0, time.UTC)}},
+		{IntervalWeekly, `{"Weekly Time Series": {"2020-05-15": ` + candle + `, "2020-05-08": ` + candle + `}}`,
+			[]time.Time{time.Date(2020, 5, 8, 0, 0, 0, 0, time.UTC), time.Date(2020, 5, 15, 0, 0, 0, 0, time.UTC)}},
+		{IntervalMonthly, `{"Monthly Time Series": {"2020-04-30": ` + candle + `}}`,
+			[]time.Time{time.Date(2020, 4, 30, 0, 0, 0, 0, time.UTC)}},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test interval:%s", test.interval), func(t *testing.T) {
+			plot, err := ScrapJSONBody(test.body, PlotOptions{Interval: test.interval})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(plot) != len(test.want) {
+				t.Fatalf("wrong plot length, want %d, get %d", len(test.want), len(plot))
+			}
+			for i, date := range test.want {
+				if !plot[i].Date.Equal(date) {
+					t.Errorf("wrong date, want %s, get %s", date, plot[i].Date)
+				}
+			}
+			if plot[0].Close != 2.0 || plot[0].Volume != 100 {
+				t.Errorf("wrong candle values %+v", plot[0])
+			}
+		})
+	}
+}
+
+func TestNeedFullOutput(t *testing.T) {
+	now := time.Date(2020, 5, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		options PlotOptions
+		want    bool
+	}{
+		{"no range", PlotOptions{Interval: IntervalDaily}, false},
+		{"recent daily", PlotOptions{Interval: IntervalDaily, From: now.AddDate(0, -1, 0)}, false},
+		{"long daily", PlotOptions{Interval: IntervalDaily, From: now.AddDate(-5, 0, 0)}, true},
+		{"weekly", PlotOptions{Interval: IntervalWeekly, From: now.AddDate(-20, 0, 0)}, false},
+		{"recent intraday", PlotOptions{Interval: Interval60Min, From: now.AddDate(0, 0, -7)}, false},
+		{"long intraday", PlotOptions{Interval: Interval1Min, From: now.AddDate(0, 0, -7)}, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.options.NeedFullOutput(now); got != test.want {
+				t.Errorf("wrong result, want %v, get %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestFilterCandles(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2020, 5, d, 0, 0, 0, 0, time.UTC) }
+	candles := []Candle{{Date: day(11)}, {Date: day(12)}, {Date: day(13)}, {Date: day(14)}, {Date: day(15)}}
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     int
+	}{
+		{"no range", time.Time{}, time.Time{}, 5},
+		{"from", day(13), time.Time{}, 3},
+		{"to", time.Time{}, day(12), 2},
+		{"inside", day(12), day(14), 3},
+		{"empty", day(16), day(20), 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filtered := FilterCandles(candles, test.from, test.to)
+			if len(filtered) != test.want {
+				t.Errorf("wrong length, want %d, get %d", test.want, len(filtered))
+			}
+		})
+	}
+}
+
+func TestScrapJSONBodyAdjusted(t *testing.T) {
+	body := `{"Time Series (Daily)": {"2020-05-12": {"1. open": "100", "2. high": "102", "3. low": "98", "4. close": "100",
+		"5. adjusted close": "50", "6. volume": "20", "7. dividend amount": "1.0", "8. split coefficient": "2.0"}}}`
+	plot, err := ScrapJSONBody(body, PlotOptions{Interval: IntervalDaily, Adjusted: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plot) != 1 || plot[0].AdjustedClose != 50 || plot[0].Volume != 20 || plot[0].DividendAmount != 1 || plot[0].SplitCoefficient != 2 {
+		t.Errorf("wrong adjusted candle %+v", plot)
+	}
+	weekly := `{"Weekly Adjusted Time Series": {"2020-05-15": {"1. open": "100", "2. high": "102", "3. low": "98", "4. close": "100",
+		"5. adjusted close": "50", "6. volume": "20", "7. dividend amount": "0.0000"}}}`
+	plot, err = ScrapJSONBody(weekly, PlotOptions{Interval: IntervalWeekly, Adjusted: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plot) != 1 || plot[0].SplitCoefficient != 1 {
+		t.Errorf("wrong weekly adjusted candle %+v", plot)
+	}
+}
+
+func TestScrapJSONBodyMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"not json", `<html></html>`},
+		{"no series", `{"Meta Data": {}}`},
+		{"wrong series type", `{"Time Series (Daily)": "Note"}`},
+		{"wrong date", `{"Time Series (Daily)": {"12.05.2020": {"1. open": "1", "2. high": "1", "3. low": "1", "4. close": "1", "5. volume": "1"}}}`},
+		{"missing price", `{"Time Series (Daily)": {"2020-05-12": {"1. open": "1", "2. high": "1", "3. low": "1", "5. volume": "1"}}}`},
+		{"wrong volume", `{"Time Series (Daily)": {"2020-05-12": {"1. open": "1", "2. high": "1", "3. low": "1", "4. close": "1", "5. volume": "x"}}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ScrapJSONBody(test.body, PlotOptions{Interval: IntervalDaily})
+			if !errors.Is(err, alphavantage.ErrMalformedResponse) {
+				t.Errorf("wrong error %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package plot
+
+import (
+	"time"
+)
+
+// Метод возвращающий true если в указанную дату проходят торги на NYSE (будний день и не праздник биржи)
+func IsTradingDay(date time.Time) bool {
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return false
+	}
+	return !IsHoliday(date)
+}
+
+// Часовой пояс NYSE, если база часовых поясов недоступна используется EST без перехода на летнее время
+var newYork = loadNewYork()
+
+// Вспомогательный метод загружающий часовой пояс America/New_York
+func loadNewYork() *time.Location {
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return time.FixedZone("EST", -5*60*60)
+	}
+	return location
+}
+
+// Метод возвращающий момент ближайшего закрытия NYSE (16:00 по Нью-Йорку) после now,
+// если сегодня торги уже закончились или сегодня не торговый день - закрытие следующего торгового дня
+func NextMarketClose(now time.Time) time.Time {
+	local := now.In(newYork)
+	day := date(local.Year(), local.Month(), local.Day())
+	for {
+		closeTime := time.Date(day.Year(), day.Month(), day.Day(), 16, 0, 0, 0, newYork)
+		if IsTradingDay(day) && closeTime.After(now) {
+			return closeTime
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+}
+
+// Метод возвращающий момент когда у графика класса assetClass закончится текущая дневная свеча: для акций - закрытие NYSE,
+// для криптовалют - ближайшая полночь UTC (торги идут круглосуточно без выходных), для валютных пар - ближайшая полночь UTC,
+// но в выходные новых свечей нет, поэтому в субботу и воскресенье ждется полночь понедельника
+func NextDailyClose(now time.Time, assetClass AssetClass) time.Time {
+	switch assetClass {
+	case AssetClassCrypto:
+		return TruncateDay(now.UTC()).AddDate(0, 0, 1)
+	case AssetClassFX:
+		boundary := TruncateDay(now.UTC()).AddDate(0, 0, 1)
+		if boundary.Weekday() == time.Sunday {
+			boundary = boundary.AddDate(0, 0, 1)
+		}
+		return boundary
+	}
+	return NextMarketClose(now)
+}
+
+// Метод возвращающий true если указанная дата является праздником NYSE (специальные закрытия биржи не учитываются)
+func IsHoliday(date time.Time) bool {
+	year, month, day := date.Date()
+	for _, holiday := range holidays(year) {
+		if holiday.Month() == month && holiday.Day() == day {
+			return true
+		}
+	}
+	return false
+}
+
+// Вспомогательный метод возвращающий даты праздников NYSE в указанном году с учетом переноса на ближайший будний день
+func holidays(year int) []time.Time {
+	dates := []time.Time{
+		newYearObserved(year),
+		nthWeekday(year, time.January, time.Monday, 3),    // день Мартина Лютера Кинга
+		nthWeekday(year, time.February, time.Monday, 3),   // день Вашингтона
+		easter(year).AddDate(0, 0, -2),                    // Страстная пятница
+		lastWeekday(year, time.May, time.Monday),          // день памяти
+		observed(date(year, time.July, 4)),                // день независимости
+		nthWeekday(year, time.September, time.Monday, 1),  // день труда
+		nthWeekday(year, time.November, time.Thursday, 4), // день благодарения
+		observed(date(year, time.December, 25)),           // Рождество
+	}
+	if year >= 2022 {
+		dates = append(dates, observed(date(year, time.June, 19))) // Juneteenth
+	}
+	return dates
+}
+
+// Вспомогательный метод создающий дату без времени
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Вспомогательный метод переносящий праздник с субботы на пятницу и с воскресенья на понедельник
+func observed(holiday time.Time) time.Time {
+	switch holiday.Weekday() {
+	case time.Saturday:
+		return holiday.AddDate(0, 0, -1)
+	case time.Sunday:
+		return holiday.AddDate(0, 0, 1)
+	}
+	return holiday
+}
+
+// Вспомогательный метод возвращающий выходной на Новый год, NYSE не переносит его с субботы на 31 декабря
+func newYearObserved(year int) time.Time {
+	holiday := date(year, time.January, 1)
+	if holiday.Weekday() == time.Sunday {
+		return holiday.AddDate(0, 0, 1)
+	}
+	return holiday
+}
+
+// Вспомогательный метод возвращающий n-й день недели weekday в месяце
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	first := date(year, month, 1)
+	shift := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, shift+7*(n-1))
+}
+
+// Вспомогательный метод возвращающий последний день недели weekday в месяце
+func lastWeekday(year int, month time.Month, weekday time.Weekday) time.Time {
+	last := date(year, month+1, 0)
+	shift := (int(last.Weekday()) - int(weekday) + 7) % 7
+	return last.AddDate(0, 0, -shift)
+}
+
+// Вспомогательный метод вычисляющий дату католической Пасхи (алгоритм Гаусса в варианте Meeus/Jones/Butcher)
+func easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return date(year, time.Month(month), day)
+}
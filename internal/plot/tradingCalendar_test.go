@@ -0,0 +1,80 @@
+package plot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsTradingDay(t *testing.T) {
+	tests := []struct {
+		name string
+		date time.Time
+		want bool
+	}{
+		{"regular day", date(2020, time.May, 12), true},
+		{"saturday", date(2020, time.May, 16), false},
+		{"new year", date(2020, time.January, 1), false},
+		{"new year on saturday", date(2021, time.December, 31), true},
+		{"mlk day", date(2020, time.January, 20), false},
+		{"good friday", date(2020, time.April, 10), false},
+		{"memorial day", date(2020, time.May, 25), false},
+		{"independence day observed", date(2020, time.July, 3), false},
+		{"juneteenth observed", date(2022, time.June, 20), false},
+		{"juneteenth before 2022", date(2020, time.June, 19), true},
+		{"thanksgiving", date(2020, time.November, 26), false},
+		{"christmas observed", date(2021, time.December, 24), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsTradingDay(test.date); got != test.want {
+				t.Errorf("wrong result for %s, want %v, get %v", test.date.Format("2006-01-02"), test.want, got)
+			}
+		})
+	}
+}
+
+func TestNextMarketClose(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"before close", time.Date(2020, time.May, 12, 14, 0, 0, 0, newYork), time.Date(2020, time.May, 12, 16, 0, 0, 0, newYork)},
+		{"after close", time.Date(2020, time.May, 12, 17, 0, 0, 0, newYork), time.Date(2020, time.May, 13, 16, 0, 0, 0, newYork)},
+		{"friday evening", time.Date(2020, time.May, 15, 20, 0, 0, 0, newYork), time.Date(2020, time.May, 18, 16, 0, 0, 0, newYork)},
+		{"before holiday", time.Date(2020, time.April, 9, 18, 0, 0, 0, newYork), time.Date(2020, time.April, 13, 16, 0, 0, 0, newYork)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := NextMarketClose(test.now); !got.Equal(test.want) {
+				t.Errorf("wrong market close, want %s, get %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestNextDailyClose(t *testing.T) {
+	tests := []struct {
+		name       string
+		assetClass AssetClass
+		now        time.Time
+		want       time.Time
+	}{
+		{"equity friday evening", AssetClassEquity, time.Date(2020, time.May, 15, 20, 0, 0, 0, newYork), time.Date(2020, time.May, 18, 16, 0, 0, 0, newYork)},
+		{"crypto friday evening", AssetClassCrypto, time.Date(2020, time.May, 15, 20, 0, 0, 0, newYork), time.Date(2020, time.May, 17, 0, 0, 0, 0, time.UTC)},
+		{"crypto saturday", AssetClassCrypto, time.Date(2020, time.May, 16, 10, 0, 0, 0, time.UTC), time.Date(2020, time.May, 17, 0, 0, 0, 0, time.UTC)},
+		{"crypto holiday", AssetClassCrypto, time.Date(2020, time.December, 25, 10, 0, 0, 0, time.UTC), time.Date(2020, time.December, 26, 0, 0, 0, 0, time.UTC)},
+		{"fx weekday", AssetClassFX, time.Date(2020, time.May, 13, 10, 0, 0, 0, time.UTC), time.Date(2020, time.May, 14, 0, 0, 0, 0, time.UTC)},
+		{"fx friday", AssetClassFX, time.Date(2020, time.May, 15, 10, 0, 0, 0, time.UTC), time.Date(2020, time.May, 16, 0, 0, 0, 0, time.UTC)},
+		{"fx saturday", AssetClassFX, time.Date(2020, time.May, 16, 10, 0, 0, 0, time.UTC), time.Date(2020, time.May, 18, 0, 0, 0, 0, time.UTC)},
+		{"fx sunday", AssetClassFX, time.Date(2020, time.May, 17, 10, 0, 0, 0, time.UTC), time.Date(2020, time.May, 18, 0, 0, 0, 0, time.UTC)},
+		{"fx nyse holiday", AssetClassFX, time.Date(2020, time.April, 10, 10, 0, 0, 0, time.UTC), time.Date(2020, time.April, 11, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := NextDailyClose(test.now, test.assetClass); !got.Equal(test.want) {
+				t.Errorf("wrong daily close, want %s, get %s", test.want, got)
+			}
+		})
+	}
+}
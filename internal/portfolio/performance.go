@@ -0,0 +1,270 @@
+package portfolio
+
+import (
+	"InvestmentHelpver_V2/internal/db"
+	"InvestmentHelpver_V2/internal/plot"
+
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Название ряда портфеля в результатах сравнения с бенчмарком
+const PortfolioSeries = "portfolio"
+
+// База к которой нормализуются индексы портфеля и бенчмарка
+const performanceBase = 100
+
+// Структура CashFlow содержит движение денег инвестора: вложение в покупки (отрицательное) или выручку от продаж (положительная)
+type CashFlow struct {
+	Date   time.Time
+	Amount float64
+}
+
+// Структура Performance содержит динамику портфеля по дням: стоимость позиций, индекс доходности взвешенной по времени
+// и бенчмарк на те же даты, оба индекса нормализованы к 100 и имеют тот же формат что и графики /compare
+type Performance struct {
+	From                time.Time
+	To                  time.Time
+	Value               []plot.Candle        // стоимость позиций на закрытие дня (Open, High, Low и Close равны)
+	Portfolio           plot.ComparedSeries  // индекс доходности портфеля взвешенной по времени
+	Benchmark           *plot.ComparedSeries `json:",omitempty"` // бенчмарк на датах портфеля, nil если не передан
+	Flows               []CashFlow           // вложения и изъятия инвестора по датам
+	TimeWeightedReturn  float64              // доходность взвешенная по времени за весь период, не зависит от вложений и изъятий
+	MoneyWeightedReturn *float64             `json:",omitempty"` // годовая доходность взвешенная по деньгам (IRR), nil если ее не удалось найти
+	ExcessReturn        *float64             `json:",omitempty"` // разница доходностей портфеля и бенчмарка за период
+}
+
+var errNoTransactions = errors.New("noTransactions")
+var errNoIRR = errors.New("noIRR")
+
+// Метод получающий дневные графики символов портфеля и бенчмарка через PlotManager (параллельно, с даты первой операции)
+// и считающий динамику портфеля, пустой benchmark - без сравнения
+func GetPerformance(transactions []db.Transaction, plotManager plot.PlotManager, benchmark string) (Performance, error) {
+	if len(transactions) == 0 {
+		return Performance{}, errNoTransactions
+	}
+	start := transactions[0].Date
+	symbols := []string{}
+	seen := map[string]bool{}
+	for _, transaction := range transactions {
+		if transaction.Date.Before(start) {
+			start = transaction.Date
+		}
+		symbol := strings.ToUpper(transaction.Symbol)
+		if !seen[symbol] {
+			seen[symbol] = true
+			symbols = append(symbols, symbol)
+		}
+	}
+	if benchmark != "" {
+		symbols = append(symbols, benchmark)
+	}
+	// бенчмарк нужен и до первой операции, чтобы на первую дату портфеля уже была цена закрытия
+	options := plot.PlotOptions{Interval: plot.IntervalDaily, From: plot.TruncateDay(start).AddDate(0, 0, -14)}
+	plots, err := plot.GetPlots(plotManager, symbols, options)
+	if err != nil {
+		return Performance{}, err
+	}
+	holdings := map[string][]plot.Candle{}
+	for i, symbol := range symbols[:len(seen)] {
+		holdings[symbol] = plots[i]
+	}
+	var benchmarkPlot []plot.Candle
+	if benchmark != "" {
+		benchmarkPlot = plots[len(plots)-1]
+	}
+	return CalculatePerformance(transactions, holdings, benchmark, benchmarkPlot)
+}
+
+// Метод считающий динамику портфеля по операциям и дневным графикам символов (ключ - символ в верхнем регистре).
+// Даты ряда - даты свечей и операций начиная с первой операции, цены закрытия переносятся на даты без свечей,
+// до первой свечи символ оценивается по цене операции. Операции считаются совершенными в конце дня: доходность дня
+// (V - B + S) / V0 - 1, где V0 и V - стоимость позиций на прошлое и текущее закрытие, B - вложения в покупки,
+// S - выручка от продаж за день; если позиций не было - (V + S) / B - 1, то есть от себестоимости купленного
+func CalculatePerformance(transactions []db.Transaction, plots map[string][]plot.Candle, benchmarkSymbol string, benchmark []plot.Candle) (Performance, error) {
+	if len(transactions) == 0 {
+		return Performance{}, errNoTransactions
+	}
+	if _, err := Positions(transactions, MethodFIFO); err != nil {
+		return Performance{}, err
+	}
+	sorted := make([]db.Transaction, len(transactions))
+	copy(sorted, transactions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+	dates := performanceDates(sorted, plots)
+
+	performance := Performance{From: dates[0], To: dates[len(dates)-1], Flows: []CashFlow{}}
+	quantities := map[string]float64{}
+	prices := map[string]float64{}
+	cursors := map[string]int{}
+	next, previous, growth := 0, 0.0, 1.0
+	index := make([]plot.Candle, 0, len(dates))
+	for _, date := range dates {
+		for symbol, candles := range plots {
+			for cursors[symbol] < len(candles) && !plot.TruncateDay(candles[cursors[symbol]].Date).After(date) {
+				prices[symbol] = candles[cursors[symbol]].Close
+				cursors[symbol]++
+			}
+		}
+		inflow, outflow := 0.0, 0.0
+		for ; next < len(sorted) && !plot.TruncateDay(sorted[next].Date).After(date); next++ {
+			transaction := sorted[next]
+			symbol := strings.ToUpper(transaction.Symbol)
+			amount := transaction.Quantity * transaction.Price
+			if transaction.Type == db.TransactionBuy {
+				quantities[symbol] += transaction.Quantity
+				inflow += amount + transaction.Fees
+			} else {
+				quantities[symbol] -= transaction.Quantity
+				outflow += amount - transaction.Fees
+			}
+			if _, ok := prices[symbol]; !ok {
+				prices[symbol] = transaction.Price
+			}
+		}
+		value := 0.0
+		for symbol, quantity := range quantities {
+			if quantity > quantityEpsilon {
+				value += quantity * prices[symbol]
+			}
+		}
+		switch {
+		case previous > 0:
+			growth *= (value - inflow + outflow) / previous
+		case inflow > 0:
+			growth *= (value + outflow) / inflow
+		}
+		if inflow > 0 {
+			performance.Flows = append(performance.Flows, CashFlow{Date: date, Amount: -inflow})
+		}
+		if outflow > 0 {
+			performance.Flows = append(performance.Flows, CashFlow{Date: date, Amount: outflow})
+		}
+		performance.Value = append(performance.Value, flatCandle(date, value))
+		index = append(index, flatCandle(date, performanceBase*growth))
+		previous = value
+	}
+	performance.TimeWeightedReturn = growth - 1
+	performance.Portfolio = plot.ComparedSeries{Symbol: PortfolioSeries, Candles: index, TotalReturn: growth - 1}
+
+	flows := performance.Flows
+	if previous > 0 {
+		flows = append(append([]CashFlow{}, flows...), CashFlow{Date: performance.To, Amount: previous})
+	}
+	if irr, err := IRR(flows); err == nil {
+		performance.MoneyWeightedReturn = &irr
+	}
+
+	if benchmarkSymbol != "" {
+		series := alignBenchmark(dates, benchmark)
+		compared := plot.ComparedSeries{Symbol: benchmarkSymbol, Candles: plot.Normalize(series, performanceBase)}
+		if len(series) > 0 && series[0].Close != 0 {
+			compared.TotalReturn = series[len(series)-1].Close/series[0].Close - 1
+			excess := performance.TimeWeightedReturn - compared.TotalReturn
+			performance.ExcessReturn = &excess
+		}
+		performance.Benchmark = &compared
+	}
+	return performance, nil
+}
+
+// Метод находящий годовую доходность взвешенную по деньгам (XIRR): ставку при которой сумма дисконтированных
+// движений денег равна нулю, время считается в днях от первого движения (365 дней в году); ищется делением отрезка пополам
+func IRR(flows []CashFlow) (float64, error) {
+	positive, negative := false, false
+	for _, flow := range flows {
+		positive = positive || flow.Amount > 0
+		negative = negative || flow.Amount < 0
+	}
+	if !positive || !negative {
+		return 0, errNoIRR
+	}
+	start := flows[0].Date
+	for _, flow := range flows {
+		if flow.Date.Before(start) {
+			start = flow.Date
+		}
+	}
+	npv := func(rate float64) float64 {
+		sum := 0.0
+		for _, flow := range flows {
+			years := flow.Date.Sub(start).Hours() / 24 / 365
+			sum += flow.Amount / math.Pow(1+rate, years)
+		}
+		return sum
+	}
+	low, high := -0.9999, 1.0
+	lowValue := npv(low)
+	for high < 1e6 && sameSign(lowValue, npv(high)) {
+		high *= 2
+	}
+	if sameSign(lowValue, npv(high)) {
+		return 0, errNoIRR
+	}
+	for i := 0; i < 200 && high-low > 1e-12; i++ {
+		middle := (low + high) / 2
+		if sameSign(lowValue, npv(middle)) {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+	return (low + high) / 2, nil
+}
+
+// Вспомогательный метод возвращающий true если числа одного знака
+func sameSign(a, b float64) bool {
+	return (a > 0) == (b > 0)
+}
+
+// Вспомогательный метод возвращающий отсортированные даты ряда: даты операций и даты свечей не раньше первой операции
+func performanceDates(sorted []db.Transaction, plots map[string][]plot.Candle) []time.Time {
+	start := plot.TruncateDay(sorted[0].Date)
+	set := map[int64]time.Time{}
+	for _, transaction := range sorted {
+		date := plot.TruncateDay(transaction.Date)
+		set[date.Unix()] = date
+	}
+	for _, candles := range plots {
+		for _, candle := range candles {
+			if date := plot.TruncateDay(candle.Date); !date.Before(start) {
+				set[date.Unix()] = date
+			}
+		}
+	}
+	dates := make([]time.Time, 0, len(set))
+	for _, date := range set {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+	return dates
+}
+
+// Вспомогательный метод переносящий цены закрытия бенчмарка на даты портфеля, ряд начинается с первой даты
+// на которую у бенчмарка уже есть цена закрытия
+func alignBenchmark(dates []time.Time, benchmark []plot.Candle) []plot.Candle {
+	series := []plot.Candle{}
+	cursor, price, ok := 0, 0.0, false
+	for _, date := range dates {
+		for cursor < len(benchmark) && !plot.TruncateDay(benchmark[cursor].Date).After(date) {
+			price, ok = benchmark[cursor].Close, true
+			cursor++
+		}
+		if ok {
+			series = append(series, flatCandle(date, price))
+		}
+	}
+	return series
+}
+
+// Вспомогательный метод создающий свечу с одинаковыми ценами, чтобы ряд значений можно было нарисовать как график
+func flatCandle(date time.Time, value float64) plot.Candle {
+	return plot.Candle{Date: date, Open: value, High: value, Low: value, Close: value}
+}
@@ -0,0 +1,179 @@
+package portfolio
+
+import (
+	"InvestmentHelpver_V2/internal/db"
+	"InvestmentHelpver_V2/internal/plot"
+
+	"math"
+	"testing"
+	"time"
+)
+
+// Вспомогательный метод создающий дневные свечи по ценам закрытия на дни января 2020, день 0 - 31 декабря 2019
+func closeCandles(closes map[int]float64) []plot.Candle {
+	candles := []plot.Candle{}
+	for day := -1; day <= 31; day++ {
+		if price, ok := closes[day]; ok {
+			candles = append(candles, flatCandle(time.Date(2020, 1, day, 0, 0, 0, 0, time.UTC), price))
+		}
+	}
+	return candles
+}
+
+// Покупка 10 по 10 и еще 10 по 12, продажа всех 20 по 12 в день без свечи
+var performanceTransactions = []db.Transaction{
+	testTransaction("IBM", db.TransactionSell, 4, 20, 12, 0),
+	testTransaction("IBM", db.TransactionBuy, 1, 10, 10, 0),
+	testTransaction("IBM", db.TransactionBuy, 3, 10, 12, 0),
+}
+
+func TestCalculatePerformance(t *testing.T) {
+	plots := map[string][]plot.Candle{"IBM": closeCandles(map[int]float64{0: 9, 1: 10, 2: 11, 3: 12})}
+	benchmark := closeCandles(map[int]float64{0: 50, 2: 55, 4: 60})
+	performance, err := CalculatePerformance(performanceTransactions, plots, "SPY", benchmark)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !performance.From.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) || !performance.To.Equal(time.Date(2020, 1, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("wrong period %v - %v", performance.From, performance.To)
+	}
+	wantValue := []float64{100, 110, 240, 0}
+	wantIndex := []float64{100, 110, 120, 120}
+	wantBenchmark := []float64{100, 110, 110, 120}
+	if len(performance.Value) != 4 || len(performance.Portfolio.Candles) != 4 || len(performance.Benchmark.Candles) != 4 {
+		t.Fatalf("wrong series length %+v", performance)
+	}
+	for i := range wantValue {
+		if !almostEqual(performance.Value[i].Close, wantValue[i]) || !almostEqual(performance.Portfolio.Candles[i].Close, wantIndex[i]) ||
+			!almostEqual(performance.Benchmark.Candles[i].Close, wantBenchmark[i]) {
+			t.Errorf("wrong point %d: value %f, index %f, benchmark %f", i, performance.Value[i].Close,
+				performance.Portfolio.Candles[i].Close, performance.Benchmark.Candles[i].Close)
+		}
+		if !performance.Benchmark.Candles[i].Date.Equal(performance.Value[i].Date) {
+			t.Errorf("benchmark date %v differs from portfolio date %v", performance.Benchmark.Candles[i].Date, performance.Value[i].Date)
+		}
+	}
+	if !almostEqual(performance.TimeWeightedReturn, 0.2) || performance.Portfolio.Symbol != PortfolioSeries ||
+		!almostEqual(performance.Benchmark.TotalReturn, 0.2) || performance.Benchmark.Symbol != "SPY" || !almostEqual(*performance.ExcessReturn, 0) {
+		t.Errorf("wrong returns %+v", performance)
+	}
+	wantFlows := []float64{-100, -120, 240}
+	if len(performance.Flows) != 3 {
+		t.Fatalf("wrong flows %+v", performance.Flows)
+	}
+	for i, flow := range performance.Flows {
+		if !almostEqual(flow.Amount, wantFlows[i]) {
+			t.Errorf("wrong flow %+v", flow)
+		}
+	}
+	// годовая ставка для прибыли 9% за три дня больше верхней границы поиска
+	if performance.MoneyWeightedReturn != nil {
+		t.Errorf("IRR found for three days %f", *performance.MoneyWeightedReturn)
+	}
+}
+
+func TestCalculatePerformanceIRR(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	transactions := []db.Transaction{
+		{Symbol: "IBM", Type: db.TransactionBuy, Date: start, Quantity: 10, Price: 10},
+		{Symbol: "IBM", Type: db.TransactionBuy, Date: start.AddDate(0, 6, 0), Quantity: 10, Price: 12},
+	}
+	plots := map[string][]plot.Candle{"IBM": {
+		flatCandle(start, 10),
+		flatCandle(start.AddDate(0, 6, 0), 12),
+		flatCandle(start.AddDate(1, 0, 0), 11),
+	}}
+	performance, err := CalculatePerformance(transactions, plots, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !almostEqual(performance.TimeWeightedReturn, 0.1) {
+		t.Errorf("wrong TWR %f", performance.TimeWeightedReturn)
+	}
+	if performance.MoneyWeightedReturn == nil {
+		t.Fatal("IRR not found")
+	}
+	// вложено 220 и позиция стоит 220, поэтому доходность взвешенная по деньгам нулевая, хотя взвешенная по времени 10%
+	irr := *performance.MoneyWeightedReturn
+	flows := append(performance.Flows, CashFlow{Date: performance.To, Amount: 220})
+	if math.Abs(irr) > 1e-6 || math.Abs(presentValue(flows, irr)) > 1e-6 {
+		t.Errorf("wrong IRR %f", irr)
+	}
+}
+
+func TestCalculatePerformanceWithoutPrices(t *testing.T) {
+	transactions := []db.Transaction{testTransaction("NEW", db.TransactionBuy, 2, 5, 20, 1)}
+	plots := map[string][]plot.Candle{"NEW": closeCandles(map[int]float64{3: 22})}
+	performance, err := CalculatePerformance(transactions, plots, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// до первой свечи позиция оценивается по цене покупки, комиссия снижает доходность
+	if len(performance.Value) != 2 || performance.Value[0].Close != 100 || performance.Value[1].Close != 110 {
+		t.Fatalf("wrong value %+v", performance.Value)
+	}
+	if !almostEqual(performance.TimeWeightedReturn, 110.0/101-1) || performance.Benchmark != nil || performance.ExcessReturn != nil {
+		t.Errorf("wrong performance %+v", performance)
+	}
+}
+
+func TestCalculatePerformanceErrors(t *testing.T) {
+	if _, err := CalculatePerformance(nil, nil, "", nil); err != errNoTransactions {
+		t.Errorf("wrong error %v", err)
+	}
+	transactions := []db.Transaction{testTransaction("IBM", db.TransactionSell, 1, 1, 10, 0)}
+	if _, err := CalculatePerformance(transactions, nil, "", nil); err != errNotEnoughQuantity {
+		t.Errorf("wrong error %v", err)
+	}
+}
+
+// Вспомогательный метод считающий приведенную стоимость движений денег по годовой ставке
+func presentValue(flows []CashFlow, rate float64) float64 {
+	sum := 0.0
+	for _, flow := range flows {
+		sum += flow.Amount / math.Pow(1+rate, flow.Date.Sub(flows[0].Date).Hours()/24/365)
+	}
+	return sum
+}
+
+func TestIRR(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		flows []CashFlow
+		want  float64
+		err   bool
+	}{
+		{"one year", []CashFlow{{start, -100}, {start.AddDate(0, 0, 365), 110}}, 0.1, false},
+		{"loss", []CashFlow{{start, -100}, {start.AddDate(0, 0, 365), 80}}, -0.2, false},
+		{"two years", []CashFlow{{start, -100}, {start.AddDate(0, 0, 730), 121}}, 0.1, false},
+		{"no income", []CashFlow{{start, -100}, {start.AddDate(0, 0, 365), -10}}, 0, true},
+		{"empty", nil, 0, true},
+	}
+	for _, test := range tests {
+		irr, err := IRR(test.flows)
+		if (err != nil) != test.err || math.Abs(irr-test.want) > 1e-6 {
+			t.Errorf("%s: IRR = %f, %v", test.name, irr, err)
+		}
+	}
+}
+
+func TestGetPerformance(t *testing.T) {
+	plotManager := testPlotManager{
+		"IBM": closeCandles(map[int]float64{1: 10, 2: 11, 3: 12}),
+		"SPY": closeCandles(map[int]float64{1: 50, 3: 60}),
+	}
+	performance, err := GetPerformance(performanceTransactions, plotManager, "SPY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if performance.Benchmark == nil || !almostEqual(performance.Benchmark.TotalReturn, 0.2) || len(performance.Value) != 4 {
+		t.Errorf("wrong performance %+v", performance)
+	}
+	if _, err := GetPerformance(performanceTransactions, plotManager, "QQQ"); err == nil {
+		t.Error("no error for unknown benchmark")
+	}
+	if _, err := GetPerformance(nil, plotManager, ""); err != errNoTransactions {
+		t.Errorf("wrong error %v", err)
+	}
+}
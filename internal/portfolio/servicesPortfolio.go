@@ -0,0 +1,210 @@
+package portfolio
+
+import (
+	"InvestmentHelpver_V2/internal/db"
+	"InvestmentHelpver_V2/internal/plot"
+
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Способ расчета себестоимости позиции: fifo - продаются сначала самые старые лоты, average - по средней цене
+type Method string
+
+const (
+	MethodFIFO    Method = "fifo"
+	MethodAverage Method = "average"
+)
+
+// Метод возвращающий способ расчета себестоимости по названию, пустое название - fifo
+func ParseMethod(s string) (Method, error) {
+	switch method := Method(strings.ToLower(s)); method {
+	case "":
+		return MethodFIFO, nil
+	case MethodFIFO, MethodAverage:
+		return method, nil
+	}
+	return "", errors.New("wrongMethod")
+}
+
+// Структура Lot содержит непроданную часть покупки, Price - цена за единицу с учетом комиссии покупки
+type Lot struct {
+	Date     time.Time
+	Quantity float64
+	Price    float64
+}
+
+// Структура Position содержит позицию по одному символу, закрытые позиции (Quantity 0) остаются ради реализованной прибыли,
+// Price, PriceDate, MarketValue и UnrealizedPnL заполняются методом Value
+type Position struct {
+	Symbol        string
+	Quantity      float64
+	CostBasis     float64    // себестоимость оставшегося количества с учетом комиссий покупки
+	AverageCost   float64    // себестоимость единицы
+	RealizedPnL   float64    // прибыль от продаж за вычетом себестоимости проданного и комиссий продажи
+	Fees          float64    // сумма всех комиссий по символу
+	Price         float64    `json:",omitempty"` // последняя цена закрытия
+	PriceDate     *time.Time `json:",omitempty"` // дата последней цены закрытия
+	MarketValue   float64    // стоимость позиции по последней цене
+	UnrealizedPnL float64    // разница стоимости позиции и ее себестоимости
+	Lots          []Lot      `json:",omitempty"` // непроданные лоты для fifo
+}
+
+// Структура Summary содержит позиции портфеля и итоговые суммы по ним
+type Summary struct {
+	Method        Method
+	Positions     []Position
+	CostBasis     float64
+	MarketValue   float64
+	RealizedPnL   float64
+	UnrealizedPnL float64
+}
+
+// Допустимая погрешность при сравнении количества, чтобы продажа всей позиции частями не давала остаток из-за округления
+const quantityEpsilon = 1e-9
+
+var errWrongTransaction = errors.New("wrongTransaction")
+var errNotEnoughQuantity = errors.New("notEnoughQuantity")
+
+// Метод проверяющий операцию перед записью: известный тип, символ, дата, положительные количество и цена, неотрицательная комиссия
+func Validate(transaction db.Transaction) error {
+	if transaction.Type != db.TransactionBuy && transaction.Type != db.TransactionSell {
+		return errWrongTransaction
+	}
+	if transaction.Symbol == "" || transaction.Date.IsZero() || transaction.Quantity <= 0 || transaction.Price <= 0 || transaction.Fees < 0 {
+		return errWrongTransaction
+	}
+	return nil
+}
+
+// Метод рассчитывающий позиции по операциям пользователя в порядке дат (операции с одной датой - в порядке списка),
+// возвращает ошибку если продается больше чем есть на дату продажи; позиции отсортированы по символу
+func Positions(transactions []db.Transaction, method Method) ([]Position, error) {
+	sorted := make([]db.Transaction, len(transactions))
+	copy(sorted, transactions)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+	positions := map[string]*Position{}
+	for _, transaction := range sorted {
+		if err := Validate(transaction); err != nil {
+			return nil, err
+		}
+		symbol := strings.ToUpper(transaction.Symbol)
+		position, ok := positions[symbol]
+		if !ok {
+			position = &Position{Symbol: symbol}
+			positions[symbol] = position
+		}
+		position.Fees += transaction.Fees
+		if transaction.Type == db.TransactionBuy {
+			buy(position, transaction)
+			continue
+		}
+		if err := sell(position, transaction, method); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]Position, 0, len(positions))
+	for _, position := range positions {
+		if position.Quantity > 0 {
+			position.AverageCost = position.CostBasis / position.Quantity
+		}
+		if method != MethodFIFO {
+			position.Lots = nil
+		}
+		result = append(result, *position)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Symbol < result[j].Symbol
+	})
+	return result, nil
+}
+
+// Вспомогательный метод добавляющий покупку в позицию, комиссия покупки включается в себестоимость
+func buy(position *Position, transaction db.Transaction) {
+	cost := transaction.Quantity*transaction.Price + transaction.Fees
+	position.Quantity += transaction.Quantity
+	position.CostBasis += cost
+	position.Lots = append(position.Lots, Lot{Date: transaction.Date, Quantity: transaction.Quantity, Price: cost / transaction.Quantity})
+}
+
+// Вспомогательный метод списывающий продажу из позиции и добавляющий реализованную прибыль,
+// для fifo списываются самые старые лоты, для average - себестоимость по средней цене
+func sell(position *Position, transaction db.Transaction, method Method) error {
+	if transaction.Quantity > position.Quantity+quantityEpsilon {
+		return errNotEnoughQuantity
+	}
+	quantity := math.Min(transaction.Quantity, position.Quantity)
+	soldCost := 0.0
+	if method == MethodAverage {
+		soldCost = position.CostBasis / position.Quantity * quantity
+	}
+	// лоты ведутся и для average, чтобы позиция была согласована, но себестоимость берется по средней цене
+	remaining := quantity
+	for remaining > quantityEpsilon && len(position.Lots) > 0 {
+		lot := &position.Lots[0]
+		used := math.Min(lot.Quantity, remaining)
+		if method == MethodFIFO {
+			soldCost += used * lot.Price
+		}
+		lot.Quantity -= used
+		remaining -= used
+		if lot.Quantity <= quantityEpsilon {
+			position.Lots = position.Lots[1:]
+		}
+	}
+	position.RealizedPnL += quantity*transaction.Price - transaction.Fees - soldCost
+	position.Quantity -= quantity
+	position.CostBasis -= soldCost
+	if position.Quantity <= quantityEpsilon {
+		position.Quantity, position.CostBasis, position.Lots = 0, 0, nil
+	}
+	return nil
+}
+
+// Метод оценивающий открытые позиции по последней цене закрытия дневного графика из PlotManager и считающий итоги портфеля,
+// графики получаются параллельно, класс актива каждого символа определяется по символу; позиция без свечей за последние
+// две недели оценивается по себестоимости
+func Value(positions []Position, method Method, plotManager plot.PlotManager, now time.Time) (Summary, error) {
+	summary := Summary{Method: method, Positions: append([]Position{}, positions...)}
+	symbols := []string{}
+	for _, position := range positions {
+		if position.Quantity > 0 {
+			symbols = append(symbols, position.Symbol)
+		}
+	}
+	// двух недель хватает чтобы застать последнюю свечу даже после длинных праздников, время отбрасывается
+	// чтобы в течение дня параметры графика и ключ кэша не менялись
+	options := plot.PlotOptions{Interval: plot.IntervalDaily, From: plot.TruncateDay(now).AddDate(0, 0, -14)}
+	plots, err := plot.GetPlots(plotManager, symbols, options)
+	if err != nil {
+		return summary, err
+	}
+	prices := map[string]plot.Candle{}
+	for i, symbol := range symbols {
+		if len(plots[i]) > 0 {
+			prices[symbol] = plots[i][len(plots[i])-1]
+		}
+	}
+	for i := range summary.Positions {
+		position := &summary.Positions[i]
+		if candle, ok := prices[position.Symbol]; ok {
+			date := candle.Date
+			position.Price, position.PriceDate = candle.Close, &date
+			position.MarketValue = position.Quantity * candle.Close
+			position.UnrealizedPnL = position.MarketValue - position.CostBasis
+		} else {
+			position.MarketValue = position.CostBasis
+		}
+		summary.CostBasis += position.CostBasis
+		summary.MarketValue += position.MarketValue
+		summary.RealizedPnL += position.RealizedPnL
+		summary.UnrealizedPnL += position.UnrealizedPnL
+	}
+	return summary, nil
+}
@@ -0,0 +1,193 @@
+package portfolio
+
+import (
+	"InvestmentHelpver_V2/internal/cache"
+	"InvestmentHelpver_V2/internal/db"
+	"InvestmentHelpver_V2/internal/plot"
+
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// Вспомогательный метод создающий операцию на день day января 2020
+func testTransaction(symbol, transactionType string, day int, quantity, price, fees float64) db.Transaction {
+	date := time.Date(2020, 1, day, 0, 0, 0, 0, time.UTC)
+	return db.Transaction{UserID: "user", Symbol: symbol, Type: transactionType, Date: date, Quantity: quantity, Price: price, Fees: fees}
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// Покупки по 10 и 20, продажа 15 штук по 30 на 3 января, операции переданы не по порядку дат
+var testTransactions = []db.Transaction{
+	testTransaction("ibm", db.TransactionSell, 3, 15, 30, 3),
+	testTransaction("IBM", db.TransactionBuy, 1, 10, 10, 1),
+	testTransaction("IBM", db.TransactionBuy, 2, 10, 20, 1),
+	testTransaction("AAPL", db.TransactionBuy, 2, 1, 300, 0),
+	testTransaction("AAPL", db.TransactionSell, 4, 1, 310, 0),
+}
+
+func TestPositionsFIFO(t *testing.T) {
+	positions, err := Positions(testTransactions, MethodFIFO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 2 || positions[0].Symbol != "AAPL" || positions[1].Symbol != "IBM" {
+		t.Fatalf("wrong positions %+v", positions)
+	}
+	aapl, ibm := positions[0], positions[1]
+	if aapl.Quantity != 0 || aapl.CostBasis != 0 || !almostEqual(aapl.RealizedPnL, 10) || aapl.Lots != nil {
+		t.Errorf("wrong closed position %+v", aapl)
+	}
+	// продано 10 по 10.1 и 5 по 20.1, остаток 5 по 20.1
+	if ibm.Quantity != 5 || !almostEqual(ibm.CostBasis, 100.5) || !almostEqual(ibm.AverageCost, 20.1) ||
+		!almostEqual(ibm.RealizedPnL, 450-3-101-100.5) || !almostEqual(ibm.Fees, 5) {
+		t.Errorf("wrong position %+v", ibm)
+	}
+	if len(ibm.Lots) != 1 || ibm.Lots[0].Quantity != 5 || !almostEqual(ibm.Lots[0].Price, 20.1) {
+		t.Errorf("wrong lots %+v", ibm.Lots)
+	}
+}
+
+func TestPositionsAverage(t *testing.T) {
+	positions, err := Positions(testTransactions, MethodAverage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ibm := positions[1]
+	// средняя себестоимость 302 / 20 = 15.1
+	if ibm.Quantity != 5 || !almostEqual(ibm.CostBasis, 75.5) || !almostEqual(ibm.AverageCost, 15.1) ||
+		!almostEqual(ibm.RealizedPnL, 450-3-226.5) || ibm.Lots != nil {
+		t.Errorf("wrong position %+v", ibm)
+	}
+}
+
+func TestPositionsErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		transactions []db.Transaction
+		err          error
+	}{
+		{"sell before buy", []db.Transaction{
+			testTransaction("IBM", db.TransactionBuy, 2, 10, 10, 0),
+			testTransaction("IBM", db.TransactionSell, 1, 5, 10, 0),
+		}, errNotEnoughQuantity},
+		{"sell too much", []db.Transaction{
+			testTransaction("IBM", db.TransactionBuy, 1, 10, 10, 0),
+			testTransaction("IBM", db.TransactionSell, 2, 11, 10, 0),
+		}, errNotEnoughQuantity},
+		{"wrong type", []db.Transaction{testTransaction("IBM", "short", 1, 10, 10, 0)}, errWrongTransaction},
+		{"zero quantity", []db.Transaction{testTransaction("IBM", db.TransactionBuy, 1, 0, 10, 0)}, errWrongTransaction},
+		{"negative fees", []db.Transaction{testTransaction("IBM", db.TransactionBuy, 1, 1, 10, -1)}, errWrongTransaction},
+		{"no symbol", []db.Transaction{testTransaction("", db.TransactionBuy, 1, 1, 10, 0)}, errWrongTransaction},
+	}
+	for _, test := range tests {
+		if _, err := Positions(test.transactions, MethodFIFO); err != test.err {
+			t.Errorf("%s: wrong error %v", test.name, err)
+		}
+	}
+}
+
+func TestPositionsPartialSells(t *testing.T) {
+	transactions := []db.Transaction{testTransaction("IBM", db.TransactionBuy, 1, 0.3, 10, 0)}
+	for i := 0; i < 3; i++ {
+		transactions = append(transactions, testTransaction("IBM", db.TransactionSell, 2, 0.1, 10, 0))
+	}
+	positions, err := Positions(transactions, MethodFIFO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if positions[0].Quantity != 0 || positions[0].CostBasis != 0 {
+		t.Errorf("rounding left position %+v", positions[0])
+	}
+}
+
+func TestParseMethod(t *testing.T) {
+	tests := []struct {
+		s      string
+		method Method
+		err    bool
+	}{
+		{"", MethodFIFO, false},
+		{"FIFO", MethodFIFO, false},
+		{"average", MethodAverage, false},
+		{"lifo", "", true},
+	}
+	for _, test := range tests {
+		method, err := ParseMethod(test.s)
+		if method != test.method || (err != nil) != test.err {
+			t.Errorf("ParseMethod(%q) = %q, %v", test.s, method, err)
+		}
+	}
+}
+
+// Менеджер графиков возвращающий заранее заданные свечи по символу
+type testPlotManager map[string][]plot.Candle
+
+func (plotManager testPlotManager) GetPlot(symbol string, options plot.PlotOptions) ([]plot.Candle, error) {
+	candles, ok := plotManager[symbol]
+	if !ok {
+		return nil, errors.New("unknownSymbol")
+	}
+	return candles, nil
+}
+
+func TestValue(t *testing.T) {
+	positions, _ := Positions(append(testTransactions, testTransaction("MSFT", db.TransactionBuy, 1, 2, 100, 0)), MethodFIFO)
+	priceDate := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+	plotManager := testPlotManager{
+		"IBM":  {{Date: priceDate.AddDate(0, 0, -1), Close: 24}, {Date: priceDate, Close: 25}},
+		"MSFT": {},
+	}
+	summary, err := Value(positions, MethodFIFO, plotManager, priceDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ibm, msft := summary.Positions[1], summary.Positions[2]
+	if ibm.Price != 25 || !ibm.PriceDate.Equal(priceDate) || ibm.MarketValue != 125 || !almostEqual(ibm.UnrealizedPnL, 24.5) {
+		t.Errorf("wrong valued position %+v", ibm)
+	}
+	if msft.PriceDate != nil || msft.MarketValue != 200 || msft.UnrealizedPnL != 0 {
+		t.Errorf("position without price is not valued at cost %+v", msft)
+	}
+	if !almostEqual(summary.MarketValue, 325) || !almostEqual(summary.CostBasis, 300.5) || !almostEqual(summary.UnrealizedPnL, 24.5) ||
+		!almostEqual(summary.RealizedPnL, 10+450-3-101-100.5) {
+		t.Errorf("wrong summary %+v", summary)
+	}
+	if positions[1].Price != 0 {
+		t.Error("Value changed positions passed to it")
+	}
+	if _, err := Value(positions, MethodFIFO, testPlotManager{}, priceDate); err == nil {
+		t.Error("no error for unknown symbol")
+	}
+}
+
+// Менеджер графиков считающий обращения к нему
+type countingPlotManager struct {
+	candles []plot.Candle
+	calls   *int
+}
+
+func (plotManager countingPlotManager) GetPlot(symbol string, options plot.PlotOptions) ([]plot.Candle, error) {
+	*plotManager.calls++
+	return plotManager.candles, nil
+}
+
+func TestValueCacheKey(t *testing.T) {
+	calls := 0
+	plotManager := countingPlotManager{candles: closeCandles(map[int]float64{1: 10}), calls: &calls}
+	cacheManager := cache.NewCacheManager(plotManager, nil, cache.NewBackendLRU(10), cache.TTL{})
+	positions, _ := Positions(testTransactions, MethodFIFO)
+	morning := time.Date(2020, 1, 10, 9, 30, 0, 0, time.UTC)
+	for _, now := range []time.Time{morning, morning.Add(time.Second), morning.Add(5 * time.Hour)} {
+		if _, err := Value(positions, MethodFIFO, cacheManager, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("plot requested %d times during one day", calls)
+	}
+}
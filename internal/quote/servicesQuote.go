@@ -0,0 +1,152 @@
+package quote
+
+import (
+	"InvestmentHelpver_V2/internal/alphavantage"
+
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Структура Quote содержит текущую котировку финансового актива
+type Quote struct {
+	Symbol           string    // символ финансового актива
+	Price            float64   // последняя цена
+	Open             float64   // цена открытия текущего дня
+	High             float64   // наивысшая цена текущего дня
+	Low              float64   // наименьшая цена текущего дня
+	PreviousClose    float64   // цена закрытия предыдущего дня
+	Change           float64   // изменение цены относительно PreviousClose
+	ChangePercent    float64   // изменение цены относительно PreviousClose в процентах
+	Volume           int       // объем торгов текущего дня
+	LatestTradingDay time.Time // последний торговый день, формат yyyy-mm-dd
+}
+
+// интерфейс менеджера котировок, реализующие его струтуры должны иметь метод получающий символ финансового актива
+// и возвращать текущую котировку в виде экземпляра структуры Quote
+type QuoteManager interface {
+	GetQuote(string) (Quote, error) // принимает символ финансового актива, возвращает текущую котировку
+}
+
+// Реализация интерфейса QuoteManager, получает котировки функцией GLOBAL_QUOTE Alpha Vantage
+type QuoteManagerAlphaVantage struct {
+	Client *alphavantage.Client
+}
+
+// Конструктор для структуры QuoteManagerAlphaVantage, принимает настроенный клиент Alpha Vantage
+func NewQuoteManagerAlphaVantage(client *alphavantage.Client) QuoteManager {
+	quoteManager := QuoteManagerAlphaVantage{client}
+	return quoteManager
+}
+
+// Метод структуры QuoteManagerAlphaVantage, принимает символ финансового актива, возвращает текущую котировку,
+// для неизвестного символа Alpha Vantage отдает пустую котировку, в этом случае возвращается alphavantage.ErrUnknownSymbol
+func (quoteManager QuoteManagerAlphaVantage) GetQuote(symbol string) (Quote, error) {
+	body, err := quoteManager.Client.Query(url.Values{"function": {"GLOBAL_QUOTE"}, "symbol": {symbol}})
+	if err != nil {
+		return Quote{}, err
+	}
+	return ScrapQuoteBody(body)
+}
+
+// Структура globalQuoteResponse - ответ функции GLOBAL_QUOTE Alpha Vantage
+type globalQuoteResponse struct {
+	GlobalQuote struct {
+		Symbol           string `json:"01. symbol"`
+		Open             string `json:"02. open"`
+		High             string `json:"03. high"`
+		Low              string `json:"04. low"`
+		Price            string `json:"05. price"`
+		Volume           string `json:"06. volume"`
+		LatestTradingDay string `json:"07. latest trading day"`
+		PreviousClose    string `json:"08. previous close"`
+		Change           string `json:"09. change"`
+		ChangePercent    string `json:"10. change percent"`
+	} `json:"Global Quote"`
+}
+
+// Метод превращающий тело ответа GLOBAL_QUOTE в экземпляр структуры Quote,
+// если ответ не удалось разобрать возвращает ошибку оборачивающую alphavantage.ErrMalformedResponse
+func ScrapQuoteBody(body []byte) (Quote, error) {
+	var response globalQuoteResponse
+	err := json.Unmarshal(body, &response)
+	if err != nil {
+		return Quote{}, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+	}
+	values := response.GlobalQuote
+	if values.Symbol == "" {
+		return Quote{}, fmt.Errorf("%w: empty global quote", alphavantage.ErrUnknownSymbol)
+	}
+	quote := Quote{Symbol: values.Symbol}
+	fields := []struct {
+		value string
+		field *float64
+	}{
+		{values.Open, &quote.Open},
+		{values.High, &quote.High},
+		{values.Low, &quote.Low},
+		{values.Price, &quote.Price},
+		{values.PreviousClose, &quote.PreviousClose},
+		{values.Change, &quote.Change},
+		{strings.TrimSuffix(values.ChangePercent, "%"), &quote.ChangePercent},
+	}
+	for _, field := range fields {
+		*field.field, err = strconv.ParseFloat(field.value, 64)
+		if err != nil {
+			return Quote{}, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+		}
+	}
+	quote.Volume, err = strconv.Atoi(values.Volume)
+	if err != nil {
+		return Quote{}, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+	}
+	quote.LatestTradingDay, err = time.Parse("2006-01-02", values.LatestTradingDay)
+	if err != nil {
+		return Quote{}, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+	}
+	return quote, nil
+}
+
+// Структура Result содержит котировку одного символа из пакетного запроса или текст ошибки ее получения
+type Result struct {
+	Symbol string
+	Quote  *Quote `json:",omitempty"`
+	Error  string `json:",omitempty"`
+}
+
+// Метод получающий котировки нескольких символов не более чем workers запросами одновременно (0 - все символы сразу),
+// возвращает результаты в порядке символов; ошибка одного символа не мешает получить котировки остальных, поэтому
+// если квота поставщика закончится посреди пакета, результат будет частичным: у оставшихся символов будет ошибка квоты
+func GetQuotes(quoteManager QuoteManager, symbols []string, workers int) []Result {
+	results := make([]Result, len(symbols))
+	if workers <= 0 || workers > len(symbols) {
+		workers = len(symbols)
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i].Symbol = symbols[i]
+				quote, err := quoteManager.GetQuote(symbols[i])
+				if err != nil {
+					results[i].Error = err.Error()
+					continue
+				}
+				results[i].Quote = &quote
+			}
+		}()
+	}
+	for i := range symbols {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,112 @@
+package quote
+
+import (
+	"InvestmentHelpver_V2/internal/alphavantage"
+
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testQuoteBody = `{"Global Quote": {"01. symbol": "IBM", "02. open": "120.0000", "03. high": "122.5000", "04. low": "119.5000",
+	"05. price": "121.0000", "06. volume": "4000000", "07. latest trading day": "2020-05-12",
+	"08. previous close": "120.5000", "09. change": "0.5000", "10. change percent": "0.4149%"}}`
+
+func TestScrapQuoteBody(t *testing.T) {
+	quote, err := ScrapQuoteBody([]byte(testQuoteBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Quote{"IBM", 121, 120, 122.5, 119.5, 120.5, 0.5, 0.4149, 4000000, time.Date(2020, 5, 12, 0, 0, 0, 0, time.UTC)}
+	if quote != want {
+		t.Errorf("wrong quote, want %+v, get %+v", want, quote)
+	}
+
+	tests := []struct {
+		body    string
+		wantErr error
+	}{
+		{`{"Global Quote": {}}`, alphavantage.ErrUnknownSymbol},
+		{`{"Global Quote": {"01. symbol": "IBM", "05. price": "abc"}}`, alphavantage.ErrMalformedResponse},
+		{`not json`, alphavantage.ErrMalformedResponse},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test body %s", test.body), func(t *testing.T) {
+			_, err := ScrapQuoteBody([]byte(test.body))
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("wrong error, want %v, get %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGetQuotes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("function") != "GLOBAL_QUOTE" || r.URL.Query().Get("symbol") != "IBM" {
+			fmt.Fprint(w, `{"Global Quote": {}}`)
+			return
+		}
+		fmt.Fprint(w, testQuoteBody)
+	}))
+	defer server.Close()
+	client := alphavantage.NewClient(server.URL, server.Client(), alphavantage.NewKeyPool("TESTKEY"), nil)
+	quoteManager := NewQuoteManagerAlphaVantage(client)
+
+	results := GetQuotes(quoteManager, []string{"IBM", "unrealSymbol"}, 1)
+	if len(results) != 2 {
+		t.Fatalf("wrong results count, want 2, get %d", len(results))
+	}
+	if results[0].Symbol != "IBM" || results[0].Quote == nil || results[0].Quote.Price != 121 {
+		t.Errorf("wrong result %+v", results[0])
+	}
+	if results[1].Symbol != "unrealSymbol" || results[1].Quote != nil || results[1].Error == "" {
+		t.Errorf("wrong result %+v", results[1])
+	}
+}
+
+// Заглушка QuoteManager запоминающая наибольшее число одновременных запросов
+type concurrentQuoteManager struct {
+	mutex   *sync.Mutex
+	current *int
+	peak    *int
+}
+
+func (quoteManager concurrentQuoteManager) GetQuote(symbol string) (Quote, error) {
+	quoteManager.mutex.Lock()
+	*quoteManager.current++
+	if *quoteManager.current > *quoteManager.peak {
+		*quoteManager.peak = *quoteManager.current
+	}
+	quoteManager.mutex.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	quoteManager.mutex.Lock()
+	*quoteManager.current--
+	quoteManager.mutex.Unlock()
+	return Quote{Symbol: symbol}, nil
+}
+
+func TestGetQuotesWorkers(t *testing.T) {
+	symbols := []string{"A", "B", "C", "D", "E", "F", "G", "H"}
+	for _, workers := range []int{0, 1, 3, 20} {
+		t.Run(fmt.Sprintf("test %d workers", workers), func(t *testing.T) {
+			current, peak := 0, 0
+			results := GetQuotes(concurrentQuoteManager{&sync.Mutex{}, &current, &peak}, symbols, workers)
+			wantPeak := workers
+			if workers == 0 || workers > len(symbols) {
+				wantPeak = len(symbols)
+			}
+			if peak > wantPeak {
+				t.Errorf("too many parallel requests, want at most %d, get %d", wantPeak, peak)
+			}
+			for i, result := range results {
+				if result.Symbol != symbols[i] || result.Quote == nil || result.Quote.Symbol != symbols[i] {
+					t.Errorf("wrong result %d %+v", i, result)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+)
+
+// Интерфейс для поверхности рисования, на которой строится график, координаты в пикселях от левого верхнего угла
+type canvas interface {
+	Rect(x, y, width, height float64, fill color.RGBA)
+	Line(x1, y1, x2, y2 float64, stroke color.RGBA)
+	Polyline(points []point, stroke color.RGBA)
+	Text(x, y float64, anchor string, s string, fill color.RGBA) // anchor: start, middle или end
+}
+
+// Структура point содержит координаты точки на поверхности рисования
+type point struct {
+	X float64
+	Y float64
+}
+
+// Структура svgCanvas накапливает элементы SVG документа
+type svgCanvas struct {
+	width    int
+	height   int
+	elements strings.Builder
+}
+
+// Конструктор для структуры svgCanvas
+func newSVGCanvas(width, height int) *svgCanvas {
+	return &svgCanvas{width: width, height: height}
+}
+
+// Метод добавляющий в документ закрашенный прямоугольник
+func (c *svgCanvas) Rect(x, y, width, height float64, fill color.RGBA) {
+	fmt.Fprintf(&c.elements, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`+"\n",
+		x, y, math.Max(width, 1), math.Max(height, 1), hex(fill))
+}
+
+// Метод добавляющий в документ отрезок
+func (c *svgCanvas) Line(x1, y1, x2, y2 float64, stroke color.RGBA) {
+	fmt.Fprintf(&c.elements, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"/>`+"\n",
+		x1, y1, x2, y2, hex(stroke))
+}
+
+// Метод добавляющий в документ ломаную линию
+func (c *svgCanvas) Polyline(points []point, stroke color.RGBA) {
+	if len(points) < 2 {
+		return
+	}
+	coordinates := make([]string, len(points))
+	for i, p := range points {
+		coordinates[i] = fmt.Sprintf("%.1f,%.1f", p.X, p.Y)
+	}
+	fmt.Fprintf(&c.elements, `<polyline points="%s" fill="none" stroke="%s" stroke-width="1.5"/>`+"\n",
+		strings.Join(coordinates, " "), hex(stroke))
+}
+
+// Метод добавляющий в документ подпись
+func (c *svgCanvas) Text(x, y float64, anchor string, s string, fill color.RGBA) {
+	fmt.Fprintf(&c.elements, `<text x="%.1f" y="%.1f" text-anchor="%s" fill="%s" font-family="sans-serif" font-size="11">%s</text>`+"\n",
+		x, y, anchor, hex(fill), html.EscapeString(s))
+}
+
+// Метод записывающий накопленный SVG документ
+func (c *svgCanvas) WriteTo(w io.Writer) (int64, error) {
+	n, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n%s</svg>\n",
+		c.width, c.height, c.width, c.height, c.elements.String())
+	return int64(n), err
+}
+
+// Вспомогательный метод переводящий цвет в формат #rrggbb
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// Структура pngCanvas рисует график в растровое изображение, подписи в растре не выводятся,
+// так как стандартная библиотека не содержит шрифтов
+type pngCanvas struct {
+	image *image.RGBA
+}
+
+// Конструктор для структуры pngCanvas
+func newPNGCanvas(width, height int) *pngCanvas {
+	return &pngCanvas{image: image.NewRGBA(image.Rect(0, 0, width, height))}
+}
+
+// Метод закрашивающий прямоугольник, прямоугольник тоньше пикселя закрашивается шириной в один пиксель
+func (c *pngCanvas) Rect(x, y, width, height float64, fill color.RGBA) {
+	x0, y0 := int(math.Floor(x)), int(math.Floor(y))
+	x1, y1 := int(math.Ceil(x+width)), int(math.Ceil(y+height))
+	if x1 <= x0 {
+		x1 = x0 + 1
+	}
+	if y1 <= y0 {
+		y1 = y0 + 1
+	}
+	bounds := image.Rect(x0, y0, x1, y1).Intersect(c.image.Bounds())
+	for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
+		for px := bounds.Min.X; px < bounds.Max.X; px++ {
+			c.image.SetRGBA(px, py, fill)
+		}
+	}
+}
+
+// Метод рисующий отрезок толщиной в один пиксель
+func (c *pngCanvas) Line(x1, y1, x2, y2 float64, stroke color.RGBA) {
+	steps := int(math.Ceil(math.Max(math.Abs(x2-x1), math.Abs(y2-y1))))
+	if steps == 0 {
+		c.set(x1, y1, stroke)
+		return
+	}
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		c.set(x1+(x2-x1)*t, y1+(y2-y1)*t, stroke)
+	}
+}
+
+// Метод рисующий ломаную линию последовательными отрезками
+func (c *pngCanvas) Polyline(points []point, stroke color.RGBA) {
+	for i := 1; i < len(points); i++ {
+		c.Line(points[i-1].X, points[i-1].Y, points[i].X, points[i].Y, stroke)
+	}
+}
+
+// Метод пропускающий подпись, растровый график выводится без текста
+func (c *pngCanvas) Text(x, y float64, anchor string, s string, fill color.RGBA) {}
+
+// Вспомогательный метод закрашивающий пиксель, если он попадает в изображение
+func (c *pngCanvas) set(x, y float64, fill color.RGBA) {
+	px, py := int(math.Round(x)), int(math.Round(y))
+	if image.Pt(px, py).In(c.image.Bounds()) {
+		c.image.SetRGBA(px, py, fill)
+	}
+}
@@ -0,0 +1,304 @@
+package render
+
+import (
+	"InvestmentHelpver_V2/internal/indicators"
+	"InvestmentHelpver_V2/internal/plot"
+
+	"bufio"
+	"errors"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Размеры графика по умолчанию и допустимые границы размеров в пикселях
+const (
+	DefaultWidth  = 800
+	DefaultHeight = 400
+	MinWidth      = 200
+	MinHeight     = 150
+	MaxWidth      = 4000
+	MaxHeight     = 3000
+)
+
+// Структура Theme содержит цвета элементов графика
+type Theme struct {
+	Name       string
+	Background color.RGBA
+	Grid       color.RGBA
+	Text       color.RGBA
+	Up         color.RGBA   // свеча с ценой закрытия не ниже цены открытия
+	Down       color.RGBA   // свеча с ценой закрытия ниже цены открытия
+	Volume     color.RGBA   // столбцы объема торгов
+	Overlays   []color.RGBA // линии индикаторов, используются по кругу
+}
+
+// Светлая тема графика, используется по умолчанию
+var ThemeLight = Theme{
+	Name:       "light",
+	Background: color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	Grid:       color.RGBA{R: 0xe0, G: 0xe0, B: 0xe0, A: 0xff},
+	Text:       color.RGBA{R: 0x33, G: 0x33, B: 0x33, A: 0xff},
+	Up:         color.RGBA{R: 0x26, G: 0xa6, B: 0x9a, A: 0xff},
+	Down:       color.RGBA{R: 0xef, G: 0x53, B: 0x50, A: 0xff},
+	Volume:     color.RGBA{R: 0xb0, G: 0xbe, B: 0xc5, A: 0xff},
+	Overlays: []color.RGBA{
+		{R: 0x1e, G: 0x88, B: 0xe5, A: 0xff},
+		{R: 0xfb, G: 0x8c, B: 0x00, A: 0xff},
+		{R: 0x8e, G: 0x24, B: 0xaa, A: 0xff},
+	},
+}
+
+// Темная тема графика
+var ThemeDark = Theme{
+	Name:       "dark",
+	Background: color.RGBA{R: 0x13, G: 0x17, B: 0x22, A: 0xff},
+	Grid:       color.RGBA{R: 0x2a, G: 0x2e, B: 0x39, A: 0xff},
+	Text:       color.RGBA{R: 0xd1, G: 0xd4, B: 0xdc, A: 0xff},
+	Up:         color.RGBA{R: 0x26, G: 0xa6, B: 0x9a, A: 0xff},
+	Down:       color.RGBA{R: 0xef, G: 0x53, B: 0x50, A: 0xff},
+	Volume:     color.RGBA{R: 0x43, G: 0x4a, B: 0x5a, A: 0xff},
+	Overlays: []color.RGBA{
+		{R: 0x42, G: 0xa5, B: 0xf5, A: 0xff},
+		{R: 0xff, G: 0xb7, B: 0x4d, A: 0xff},
+		{R: 0xce, G: 0x93, B: 0xd8, A: 0xff},
+	},
+}
+
+// Метод возвращающий тему графика по названию (light или dark), пустое название - светлая тема
+func ParseTheme(s string) (Theme, error) {
+	switch strings.ToLower(s) {
+	case "", ThemeLight.Name:
+		return ThemeLight, nil
+	case ThemeDark.Name:
+		return ThemeDark, nil
+	}
+	return Theme{}, errors.New("wrongTheme")
+}
+
+// Структура Overlay содержит линию индикатора, которая рисуется поверх свечей в масштабе цены
+type Overlay struct {
+	Name   string
+	Points []indicators.Point
+}
+
+// Структура Options содержит параметры отрисовки графика, нулевые размеры заменяются размерами по умолчанию,
+// тема без названия заменяется светлой темой
+type Options struct {
+	Width    int
+	Height   int
+	Theme    Theme
+	Volume   bool   // рисовать столбцы объема торгов под свечами
+	Title    string // подпись в левом верхнем углу, в PNG не выводится
+	Overlays []Overlay
+}
+
+// Метод рассчитывающий индикатор и возвращающий его линии для наложения на график
+// (sma, ema, vwap - одна линия, bollinger - три линии; осцилляторы в масштабе цены не рисуются и возвращают ошибку)
+func CalculateOverlays(name string, candles []plot.Candle, period int) ([]Overlay, error) {
+	name = strings.ToLower(name)
+	switch name {
+	case "sma", "ema", "vwap", "bollinger":
+	default:
+		return nil, errors.New("wrongOverlay")
+	}
+	values, err := indicators.Calculate(name, candles, period)
+	if err != nil {
+		return nil, err
+	}
+	switch values := values.(type) {
+	case []indicators.Point:
+		return []Overlay{{Name: name, Points: values}}, nil
+	case []indicators.BandPoint:
+		upper := make([]indicators.Point, len(values))
+		middle := make([]indicators.Point, len(values))
+		lower := make([]indicators.Point, len(values))
+		for i, value := range values {
+			upper[i] = indicators.Point{Date: value.Date, Value: value.Upper}
+			middle[i] = indicators.Point{Date: value.Date, Value: value.Middle}
+			lower[i] = indicators.Point{Date: value.Date, Value: value.Lower}
+		}
+		return []Overlay{
+			{Name: name + " upper", Points: upper},
+			{Name: name + " middle", Points: middle},
+			{Name: name + " lower", Points: lower},
+		}, nil
+	}
+	return nil, errors.New("wrongOverlay")
+}
+
+// Метод рисующий свечной график в формате SVG
+func SVG(w io.Writer, candles []plot.Candle, options Options) error {
+	options, err := checkOptions(options)
+	if err != nil {
+		return err
+	}
+	c := newSVGCanvas(options.Width, options.Height)
+	draw(c, candles, options)
+	_, err = c.WriteTo(w)
+	return err
+}
+
+// Метод рисующий свечной график в формате PNG
+func PNG(w io.Writer, candles []plot.Candle, options Options) error {
+	options, err := checkOptions(options)
+	if err != nil {
+		return err
+	}
+	c := newPNGCanvas(options.Width, options.Height)
+	draw(c, candles, options)
+	buffered := bufio.NewWriter(w)
+	if err := png.Encode(buffered, c.image); err != nil {
+		return err
+	}
+	return buffered.Flush()
+}
+
+// Вспомогательный метод подставляющий значения по умолчанию и проверяющий размеры графика
+func checkOptions(options Options) (Options, error) {
+	if options.Width == 0 {
+		options.Width = DefaultWidth
+	}
+	if options.Height == 0 {
+		options.Height = DefaultHeight
+	}
+	if options.Width < MinWidth || options.Width > MaxWidth || options.Height < MinHeight || options.Height > MaxHeight {
+		return options, errors.New("wrongSize")
+	}
+	if options.Theme.Name == "" {
+		options.Theme = ThemeLight
+	}
+	return options, nil
+}
+
+// Отступы области графика от краев изображения в пикселях, справа выводится шкала цен, снизу - даты
+const (
+	marginLeft   = 10
+	marginRight  = 60
+	marginTop    = 30
+	marginBottom = 20
+	volumeGap    = 10
+	gridLines    = 4
+)
+
+// Вспомогательный метод рисующий график на поверхности рисования
+func draw(c canvas, candles []plot.Candle, options Options) {
+	theme := options.Theme
+	width, height := float64(options.Width), float64(options.Height)
+	c.Rect(0, 0, width, height, theme.Background)
+	if options.Title != "" {
+		c.Text(marginLeft, 18, "start", options.Title, theme.Text)
+	}
+	left, right := float64(marginLeft), width-marginRight
+	top, bottom := float64(marginTop), height-marginBottom
+	if len(candles) == 0 {
+		c.Text((left+right)/2, (top+bottom)/2, "middle", "no data", theme.Text)
+		return
+	}
+
+	priceBottom, volumeTop := bottom, bottom
+	if options.Volume {
+		volumeTop = bottom - (bottom-top)*0.2
+		priceBottom = volumeTop - volumeGap
+	}
+	low, high := priceRange(candles, options.Overlays)
+	y := func(price float64) float64 {
+		return top + (high-price)/(high-low)*(priceBottom-top)
+	}
+
+	for i := 0; i <= gridLines; i++ {
+		price := low + (high-low)*float64(i)/gridLines
+		c.Line(left, y(price), right, y(price), theme.Grid)
+		c.Text(right+5, y(price)+4, "start", fmt.Sprintf("%.2f", price), theme.Text)
+	}
+
+	slot := (right - left) / float64(len(candles))
+	bodyWidth := math.Max(slot*0.7, 1)
+	center := func(i int) float64 {
+		return left + slot*(float64(i)+0.5)
+	}
+
+	if options.Volume {
+		maxVolume := 0
+		for _, candle := range candles {
+			if candle.Volume > maxVolume {
+				maxVolume = candle.Volume
+			}
+		}
+		if maxVolume > 0 {
+			for i, candle := range candles {
+				barHeight := float64(candle.Volume) / float64(maxVolume) * (bottom - volumeTop)
+				c.Rect(center(i)-bodyWidth/2, bottom-barHeight, bodyWidth, barHeight, theme.Volume)
+			}
+		}
+	}
+
+	for i, candle := range candles {
+		fill := theme.Up
+		if candle.Close < candle.Open {
+			fill = theme.Down
+		}
+		c.Line(center(i), y(candle.High), center(i), y(candle.Low), fill)
+		bodyTop := y(math.Max(candle.Open, candle.Close))
+		bodyBottom := y(math.Min(candle.Open, candle.Close))
+		c.Rect(center(i)-bodyWidth/2, bodyTop, bodyWidth, bodyBottom-bodyTop, fill)
+	}
+
+	index := make(map[int64]int, len(candles))
+	for i, candle := range candles {
+		index[candle.Date.Unix()] = i
+	}
+	legend := right
+	for i, overlay := range options.Overlays {
+		stroke := theme.Text
+		if len(theme.Overlays) > 0 {
+			stroke = theme.Overlays[i%len(theme.Overlays)]
+		}
+		points := []point{}
+		for _, value := range overlay.Points {
+			if j, ok := index[value.Date.Unix()]; ok {
+				points = append(points, point{X: center(j), Y: y(value.Value)})
+			}
+		}
+		c.Polyline(points, stroke)
+		c.Text(legend, 18, "end", overlay.Name, stroke)
+		legend -= float64(len(overlay.Name))*7 + 10
+	}
+
+	c.Text(left, height-5, "start", formatDate(candles[0].Date), theme.Text)
+	if len(candles) > 1 {
+		c.Text(right, height-5, "end", formatDate(candles[len(candles)-1].Date), theme.Text)
+	}
+}
+
+// Вспомогательный метод возвращающий диапазон цен графика с учетом линий индикаторов и отступом 5% сверху и снизу
+func priceRange(candles []plot.Candle, overlays []Overlay) (float64, float64) {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, candle := range candles {
+		low = math.Min(low, candle.Low)
+		high = math.Max(high, candle.High)
+	}
+	for _, overlay := range overlays {
+		for _, value := range overlay.Points {
+			low = math.Min(low, value.Value)
+			high = math.Max(high, value.Value)
+		}
+	}
+	padding := (high - low) * 0.05
+	if padding == 0 {
+		padding = math.Max(math.Abs(high)*0.05, 1)
+	}
+	return low - padding, high + padding
+}
+
+// Вспомогательный метод форматирующий дату свечи для подписи, время выводится только у внутридневных свечей
+func formatDate(date time.Time) string {
+	if date.Hour() == 0 && date.Minute() == 0 {
+		return date.Format("2006-01-02")
+	}
+	return date.Format("2006-01-02 15:04")
+}
@@ -0,0 +1,198 @@
+package render
+
+import (
+	"InvestmentHelpver_V2/internal/plot"
+	"InvestmentHelpver_V2/internal/plot/plottest"
+
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+)
+
+// Вспомогательный метод создающий n дневных свечей, четные свечи растущие, нечетные - падающие
+func testCandles(n int) []plot.Candle {
+	candles := make([]plot.Candle, n)
+	for i := range candles {
+		price := 100 + float64(i)
+		candle := plot.Candle{
+			Date:   plottest.Start.AddDate(0, 0, i),
+			Open:   price,
+			High:   price + 2,
+			Low:    price - 2,
+			Close:  price + 1,
+			Volume: 1000 * (i + 1),
+		}
+		if i%2 == 1 {
+			candle.Open, candle.Close = candle.Close, candle.Open
+		}
+		candles[i] = candle
+	}
+	return candles
+}
+
+func TestSVG(t *testing.T) {
+	candles := testCandles(30)
+	overlays, err := CalculateOverlays("sma", candles, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buffer := &bytes.Buffer{}
+	err = SVG(buffer, candles, Options{Theme: ThemeDark, Volume: true, Title: "IBM <daily>", Overlays: overlays})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svg := buffer.String()
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		if _, err := decoder.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("invalid xml: %v", err)
+		}
+	}
+	checks := []string{
+		`width="800" height="400"`,
+		hex(ThemeDark.Background),
+		hex(ThemeDark.Up),
+		hex(ThemeDark.Down),
+		hex(ThemeDark.Volume),
+		"<polyline",
+		"IBM &lt;daily&gt;",
+		"2020-01-01",
+		"2020-01-30",
+	}
+	for _, check := range checks {
+		if !strings.Contains(svg, check) {
+			t.Errorf("svg does not contain %q", check)
+		}
+	}
+	// фон, 30 свечей и 30 столбцов объема
+	if count := strings.Count(svg, "<rect"); count != 61 {
+		t.Errorf("wrong rect count %d", count)
+	}
+}
+
+func TestSVGWithoutVolume(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	if err := SVG(buffer, testCandles(10), Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if count := strings.Count(buffer.String(), "<rect"); count != 11 {
+		t.Errorf("wrong rect count %d", count)
+	}
+	if !strings.Contains(buffer.String(), hex(ThemeLight.Background)) {
+		t.Error("default theme is not light")
+	}
+}
+
+func TestSVGEmpty(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	if err := SVG(buffer, nil, Options{Width: 300, Height: 200}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buffer.String(), "no data") {
+		t.Error("empty chart has no placeholder")
+	}
+}
+
+func TestPNG(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	if err := PNG(buffer, testCandles(20), Options{Width: 400, Height: 300, Volume: true}); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bounds := img.Bounds(); bounds.Dx() != 400 || bounds.Dy() != 300 {
+		t.Fatalf("wrong size %v", bounds)
+	}
+	up, down := false, false
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 400; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			switch {
+			case uint8(r>>8) == ThemeLight.Up.R && uint8(g>>8) == ThemeLight.Up.G && uint8(b>>8) == ThemeLight.Up.B:
+				up = true
+			case uint8(r>>8) == ThemeLight.Down.R && uint8(g>>8) == ThemeLight.Down.G && uint8(b>>8) == ThemeLight.Down.B:
+				down = true
+			}
+		}
+	}
+	if !up || !down {
+		t.Errorf("candles are not drawn, up %v, down %v", up, down)
+	}
+	if r, g, b, _ := img.At(0, 0).RGBA(); uint8(r>>8) != 0xff || uint8(g>>8) != 0xff || uint8(b>>8) != 0xff {
+		t.Error("background is not white")
+	}
+}
+
+func TestWrongSize(t *testing.T) {
+	tests := []Options{
+		{Width: 100},
+		{Height: 100},
+		{Width: MaxWidth + 1},
+		{Height: MaxHeight + 1},
+		{Width: -1},
+	}
+	for _, options := range tests {
+		if err := SVG(&bytes.Buffer{}, testCandles(5), options); err == nil || err.Error() != "wrongSize" {
+			t.Errorf("no size error for %dx%d", options.Width, options.Height)
+		}
+		if err := PNG(&bytes.Buffer{}, testCandles(5), options); err == nil || err.Error() != "wrongSize" {
+			t.Errorf("no size error for %dx%d", options.Width, options.Height)
+		}
+	}
+}
+
+func TestParseTheme(t *testing.T) {
+	tests := []struct {
+		s     string
+		theme string
+		err   bool
+	}{
+		{"", "light", false},
+		{"light", "light", false},
+		{"DARK", "dark", false},
+		{"blue", "", true},
+	}
+	for _, test := range tests {
+		theme, err := ParseTheme(test.s)
+		if (err != nil) != test.err || theme.Name != test.theme {
+			t.Errorf("ParseTheme(%q) = %q, %v", test.s, theme.Name, err)
+		}
+	}
+}
+
+func TestCalculateOverlays(t *testing.T) {
+	candles := testCandles(30)
+	tests := []struct {
+		name  string
+		lines int
+		err   bool
+	}{
+		{"sma", 1, false},
+		{"EMA", 1, false},
+		{"vwap", 1, false},
+		{"bollinger", 3, false},
+		{"rsi", 0, true},
+		{"macd", 0, true},
+		{"unknown", 0, true},
+	}
+	for _, test := range tests {
+		overlays, err := CalculateOverlays(test.name, candles, 10)
+		if (err != nil) != test.err || len(overlays) != test.lines {
+			t.Errorf("CalculateOverlays(%q) = %d lines, %v", test.name, len(overlays), err)
+		}
+	}
+	overlays, _ := CalculateOverlays("bollinger", candles, 10)
+	if overlays[0].Points[0].Value <= overlays[2].Points[0].Value {
+		t.Error("upper band is not above lower band")
+	}
+	if _, err := CalculateOverlays("sma", candles[:3], 10); err == nil {
+		t.Error("no error for short plot")
+	}
+}
@@ -0,0 +1,55 @@
+package search
+
+import (
+	"strings"
+	"sync"
+)
+
+// Максимальное количество запомненных валют, при превышении кэш очищается
+const maxCurrencies = 10000
+
+// Структура CurrencyCache запоминает валюту торгов символа найденную через SymbolSearcher, чтобы при конвертации
+// графиков поиск выполнялся один раз на символ; пустая валюта (символ не найден) тоже запоминается, ошибки поиска - нет
+type CurrencyCache struct {
+	searcher   SymbolSearcher
+	mutex      sync.Mutex
+	currencies map[string]string
+}
+
+// Конструктор для структуры CurrencyCache, принимает поиск финансовых активов, nil - поиск выключен
+func NewCurrencyCache(searcher SymbolSearcher) *CurrencyCache {
+	currencyCache := &CurrencyCache{searcher: searcher, currencies: map[string]string{}}
+	return currencyCache
+}
+
+// Метод структуры CurrencyCache, принимает символ, возвращает валюту торгов в верхнем регистре или пустую строку
+// если поиск выключен или не сообщает валюту символа
+func (currencyCache *CurrencyCache) Currency(symbol string) (string, error) {
+	if currencyCache.searcher == nil {
+		return "", nil
+	}
+	key := strings.ToUpper(symbol)
+	currencyCache.mutex.Lock()
+	currency, ok := currencyCache.currencies[key]
+	currencyCache.mutex.Unlock()
+	if ok {
+		return currency, nil
+	}
+	matches, err := currencyCache.searcher.Search(symbol)
+	if err != nil {
+		return "", err
+	}
+	for _, match := range matches {
+		if strings.EqualFold(match.Symbol, symbol) && match.Currency != "" {
+			currency = strings.ToUpper(match.Currency)
+			break
+		}
+	}
+	currencyCache.mutex.Lock()
+	if len(currencyCache.currencies) >= maxCurrencies {
+		currencyCache.currencies = map[string]string{}
+	}
+	currencyCache.currencies[key] = currency
+	currencyCache.mutex.Unlock()
+	return currency, nil
+}
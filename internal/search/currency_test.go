@@ -0,0 +1,61 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// Заглушка SymbolSearcher считающая вызовы поиска
+type countingSearcher struct {
+	matches []Match
+	err     error
+	calls   *int
+}
+
+func (searcher countingSearcher) Search(query string) ([]Match, error) {
+	*searcher.calls++
+	return searcher.matches, searcher.err
+}
+
+func TestCurrencyCache(t *testing.T) {
+	calls := 0
+	currencyCache := NewCurrencyCache(countingSearcher{matches: []Match{{Symbol: "SAP.DEX", Currency: "eur"}}, calls: &calls})
+	tests := []struct {
+		symbol    string
+		want      string
+		wantCalls int
+	}{
+		{"SAP.DEX", "EUR", 1},
+		{"sap.dex", "EUR", 1},
+		{"IBM", "", 2},
+		{"IBM", "", 2},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test currency %s", test.symbol), func(t *testing.T) {
+			currency, err := currencyCache.Currency(test.symbol)
+			if err != nil || currency != test.want {
+				t.Errorf("wrong currency, want %s, get %s, error %v", test.want, currency, err)
+			}
+			if calls != test.wantCalls {
+				t.Errorf("wrong number of searches, want %d, get %d", test.wantCalls, calls)
+			}
+		})
+	}
+
+	errorCalls := 0
+	errorCache := NewCurrencyCache(countingSearcher{err: errors.New("limit"), calls: &errorCalls})
+	for i := 0; i < 2; i++ {
+		if _, err := errorCache.Currency("IBM"); err == nil {
+			t.Error("search error is not returned")
+		}
+	}
+	if errorCalls != 2 {
+		t.Errorf("search error is cached, searches %d", errorCalls)
+	}
+
+	currency, err := NewCurrencyCache(nil).Currency("IBM")
+	if currency != "" || err != nil {
+		t.Errorf("wrong currency without searcher %s, error %v", currency, err)
+	}
+}
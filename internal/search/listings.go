@@ -0,0 +1,125 @@
+package search
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Максимальное количество активов в ответе SymbolSearcherListings
+const maxListingMatches = 10
+
+// Реализация интерфейса SymbolSearcher, ищет активы в локальном CSV файле со списком торгуемых активов,
+// поддерживается формат выгрузки LISTING_STATUS Alpha Vantage (symbol,name,exchange,assetType,ipoDate,delistingDate,status)
+// с необязательным столбцом currency
+type SymbolSearcherListings struct {
+	Listings []Match // все активы из файла
+}
+
+// Конструктор для структуры SymbolSearcherListings, считывает список активов из файла path
+func NewSymbolSearcherListings(path string) (SymbolSearcher, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	listings, err := ScrapListings(file)
+	if err != nil {
+		return nil, err
+	}
+	searcher := SymbolSearcherListings{listings}
+	return searcher, nil
+}
+
+// Метод считывающий CSV со списком активов, заголовок обязателен, столбцы ищутся по названию без учета регистра,
+// обязательны столбцы symbol и name, активы со статусом отличным от Active пропускаются
+func ScrapListings(reader io.Reader) ([]Match, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["symbol"]; !ok {
+		return nil, errors.New("missingColumn")
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, errors.New("missingColumn")
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	listings := []Match{}
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if status := field(record, "status"); status != "" && !strings.EqualFold(status, "active") {
+			continue
+		}
+		listing := Match{
+			Symbol:   field(record, "symbol"),
+			Name:     field(record, "name"),
+			Type:     field(record, "assettype"),
+			Exchange: field(record, "exchange"),
+			Currency: field(record, "currency"),
+		}
+		if listing.Symbol != "" {
+			listings = append(listings, listing)
+		}
+	}
+	return listings, nil
+}
+
+// Метод структуры SymbolSearcherListings, принимает строку запроса, возвращает список экземпляров структуры Match.
+// Оценка соответствия: 1 - символ совпадает, 0.8 - символ начинается с запроса, 0.6 - название начинается с запроса,
+// 0.4 - название содержит запрос; при равной оценке короткие символы идут раньше
+func (searcher SymbolSearcherListings) Search(query string) ([]Match, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	matches := []Match{}
+	if query == "" {
+		return matches, nil
+	}
+	for _, listing := range searcher.Listings {
+		symbol := strings.ToLower(listing.Symbol)
+		name := strings.ToLower(listing.Name)
+		switch {
+		case symbol == query:
+			listing.Score = 1
+		case strings.HasPrefix(symbol, query):
+			listing.Score = 0.8
+		case strings.HasPrefix(name, query):
+			listing.Score = 0.6
+		case strings.Contains(name, query):
+			listing.Score = 0.4
+		default:
+			continue
+		}
+		matches = append(matches, listing)
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return len(matches[i].Symbol) < len(matches[j].Symbol)
+	})
+	if len(matches) > maxListingMatches {
+		matches = matches[:maxListingMatches]
+	}
+	return matches, nil
+}
@@ -0,0 +1,89 @@
+package search
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testListings = `symbol,name,exchange,assetType,ipoDate,delistingDate,status
+IBM,International Business Machines Corp,NYSE,Stock,1962-01-02,null,Active
+TSLA,Tesla Inc,NASDAQ,Stock,2010-06-29,null,Active
+TSLL,Direxion Daily TSLA Bull 1.5X Shares,NASDAQ,ETF,2022-08-09,null,Active
+TWTR,Twitter Inc,NYSE,Stock,2013-11-07,2022-11-08,Delisted
+`
+
+func TestScrapListings(t *testing.T) {
+	listings, err := ScrapListings(strings.NewReader(testListings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listings) != 3 {
+		t.Fatalf("wrong listings count, want 3, get %d", len(listings))
+	}
+	want := Match{Symbol: "IBM", Name: "International Business Machines Corp", Type: "Stock", Exchange: "NYSE"}
+	if listings[0] != want {
+		t.Errorf("wrong listing, want %+v, get %+v", want, listings[0])
+	}
+	if _, err := ScrapListings(strings.NewReader("ticker,title\nIBM,IBM\n")); err == nil {
+		t.Error("expected missing column error")
+	}
+}
+
+func TestSymbolSearcherListings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "listings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "listings.csv")
+	if err := ioutil.WriteFile(path, []byte(testListings), 0644); err != nil {
+		t.Fatal(err)
+	}
+	searcher, err := NewSymbolSearcherListings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"tsla", []string{"TSLA", "TSLL"}},
+		{"TSL", []string{"TSLA", "TSLL"}},
+		{"tesla", []string{"TSLA"}},
+		{"business", []string{"IBM"}},
+		{"twitter", []string{}},
+		{"", []string{}},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("test query:%q", test.query), func(t *testing.T) {
+			matches, err := searcher.Search(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			symbols := []string{}
+			for _, match := range matches {
+				symbols = append(symbols, match.Symbol)
+			}
+			if strings.Join(symbols, ",") != strings.Join(test.want, ",") {
+				t.Errorf("wrong matches, want %v, get %v", test.want, symbols)
+			}
+		})
+	}
+
+	t.Run("test validate", func(t *testing.T) {
+		if ok, err := Validate(searcher, "tsla"); !ok || err != nil {
+			t.Errorf("tsla must be valid, get %v %v", ok, err)
+		}
+		if ok, err := Validate(searcher, "TSL"); ok || err != nil {
+			t.Errorf("TSL must be invalid, get %v %v", ok, err)
+		}
+	})
+
+	if _, err := NewSymbolSearcherListings(filepath.Join(dir, "missing.csv")); err == nil {
+		t.Error("expected missing file error")
+	}
+}
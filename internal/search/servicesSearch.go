@@ -0,0 +1,100 @@
+package search
+
+import (
+	"InvestmentHelpver_V2/internal/alphavantage"
+
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Структура Match содержит найденный финансовый актив и оценку соответствия запросу
+type Match struct {
+	Symbol   string  // символ финансового актива
+	Name     string  // название компании или фонда
+	Type     string  `json:",omitempty"` // тип актива, например Equity, ETF
+	Exchange string  `json:",omitempty"` // биржа, например NYSE
+	Region   string  `json:",omitempty"` // регион торгов, например United States
+	Currency string  `json:",omitempty"` // валюта торгов
+	Score    float64 // оценка соответствия запросу от 0 до 1
+}
+
+// интерфейс поиска финансовых активов, реализующие его струтуры должны иметь метод получающий строку запроса
+// (часть символа или названия компании) и возвращать найденные активы в порядке убывания оценки соответствия
+type SymbolSearcher interface {
+	Search(string) ([]Match, error) // принимает строку запроса, возвращает список экземпляров структуры Match
+}
+
+// Метод проверяющий что символ финансового актива существует, ищет его через searcher и сравнивает символы без учета регистра
+func Validate(searcher SymbolSearcher, symbol string) (bool, error) {
+	matches, err := searcher.Search(symbol)
+	if err != nil {
+		return false, err
+	}
+	for _, match := range matches {
+		if strings.EqualFold(match.Symbol, symbol) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Реализация интерфейса SymbolSearcher, ищет активы функцией SYMBOL_SEARCH Alpha Vantage
+type SymbolSearcherAlphaVantage struct {
+	Client *alphavantage.Client
+}
+
+// Конструктор для структуры SymbolSearcherAlphaVantage, принимает настроенный клиент Alpha Vantage
+func NewSymbolSearcherAlphaVantage(client *alphavantage.Client) SymbolSearcher {
+	searcher := SymbolSearcherAlphaVantage{client}
+	return searcher
+}
+
+// Метод структуры SymbolSearcherAlphaVantage, принимает строку запроса, возвращает список экземпляров структуры Match
+func (searcher SymbolSearcherAlphaVantage) Search(query string) ([]Match, error) {
+	body, err := searcher.Client.Query(url.Values{"function": {"SYMBOL_SEARCH"}, "keywords": {query}})
+	if err != nil {
+		return nil, err
+	}
+	return ScrapSearchBody(body)
+}
+
+// Структура symbolSearchResponse - ответ функции SYMBOL_SEARCH Alpha Vantage
+type symbolSearchResponse struct {
+	BestMatches []struct {
+		Symbol     string `json:"1. symbol"`
+		Name       string `json:"2. name"`
+		Type       string `json:"3. type"`
+		Region     string `json:"4. region"`
+		Currency   string `json:"8. currency"`
+		MatchScore string `json:"9. matchScore"`
+	} `json:"bestMatches"`
+}
+
+// Метод превращающий тело ответа SYMBOL_SEARCH в список структуры Match,
+// если ответ не удалось разобрать возвращает ошибку оборачивающую alphavantage.ErrMalformedResponse
+func ScrapSearchBody(body []byte) ([]Match, error) {
+	var response symbolSearchResponse
+	err := json.Unmarshal(body, &response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+	}
+	matches := make([]Match, 0, len(response.BestMatches))
+	for _, values := range response.BestMatches {
+		score, err := strconv.ParseFloat(values.MatchScore, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", alphavantage.ErrMalformedResponse, err)
+		}
+		matches = append(matches, Match{
+			Symbol:   values.Symbol,
+			Name:     values.Name,
+			Type:     values.Type,
+			Region:   values.Region,
+			Currency: values.Currency,
+			Score:    score,
+		})
+	}
+	return matches, nil
+}
@@ -0,0 +1,46 @@
+package search
+
+import (
+	"InvestmentHelpver_V2/internal/alphavantage"
+
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSymbolSearcherAlphaVantage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("function") != "SYMBOL_SEARCH" || r.URL.Query().Get("keywords") != "tesla" {
+			fmt.Fprint(w, `{"bestMatches": []}`)
+			return
+		}
+		fmt.Fprint(w, `{"bestMatches": [{"1. symbol": "TSLA", "2. name": "Tesla Inc", "3. type": "Equity",
+			"4. region": "United States", "5. marketOpen": "09:30", "6. marketClose": "16:00", "7. timezone": "UTC-04",
+			"8. currency": "USD", "9. matchScore": "0.8889"}]}`)
+	}))
+	defer server.Close()
+	client := alphavantage.NewClient(server.URL, server.Client(), alphavantage.NewKeyPool("TESTKEY"), nil)
+	searcher := NewSymbolSearcherAlphaVantage(client)
+
+	matches, err := searcher.Search("tesla")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Match{Symbol: "TSLA", Name: "Tesla Inc", Type: "Equity", Region: "United States", Currency: "USD", Score: 0.8889}
+	if len(matches) != 1 || matches[0] != want {
+		t.Errorf("wrong matches, want %+v, get %+v", want, matches)
+	}
+	matches, err = searcher.Search("unrealSymbol")
+	if err != nil || len(matches) != 0 {
+		t.Errorf("wrong matches %+v, error %v", matches, err)
+	}
+}
+
+func TestScrapSearchBody(t *testing.T) {
+	_, err := ScrapSearchBody([]byte(`{"bestMatches": [{"1. symbol": "TSLA", "9. matchScore": "abc"}]}`))
+	if !errors.Is(err, alphavantage.ErrMalformedResponse) {
+		t.Errorf("wrong error %v", err)
+	}
+}